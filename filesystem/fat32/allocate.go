@@ -0,0 +1,62 @@
+package fat32
+
+import "fmt"
+
+// allocateCluster finds a free cluster and marks it end-of-chain, returning its number. It
+// starts searching from the FSInfo next-free hint (or cluster 2 if there is none), wrapping
+// around to the start of the data region once before giving up. The FSInfo free count and
+// next-free hint are updated in memory; callers are responsible for flushing them with sync.
+func (fs *FileSystem) allocateCluster(fatTable []byte, countOfClusters uint32) (uint32, error) {
+	start := fs.nextFreeHint()
+	if start >= countOfClusters+2 {
+		start = 2
+	}
+
+	found, err := fs.scanForFree(fatTable, countOfClusters, start)
+	if err != nil {
+		return 0, err
+	}
+
+	// freeCount must be read before found is marked allocated below: if FSInfo's cached count is
+	// the fsInfoUnknown sentinel, recomputeFreeClusters falls back to a full FAT scan, which
+	// would otherwise already see found as allocated and undercount by one.
+	freeCount, ok := fs.FreeClusters()
+	if !ok {
+		freeCount = fs.recomputeFreeClusters(fatTable)
+	}
+
+	fatEntrySet(fs.fsType, fatTable, found, endOfChainMarker(fs.fsType))
+
+	if freeCount > 0 {
+		freeCount--
+	}
+	fs.updateFSInfo(freeCount, found+1)
+
+	return found, nil
+}
+
+// scanForFree walks the FAT starting at start, wrapping once, looking for the first entry
+// marked free (0x000).
+func (fs *FileSystem) scanForFree(fatTable []byte, countOfClusters, start uint32) (uint32, error) {
+	last := countOfClusters + 2
+	for _, rng := range [][2]uint32{{start, last}, {2, start}} {
+		for cluster := rng[0]; cluster < rng[1]; cluster++ {
+			if fatEntryGet(fs.fsType, fatTable, cluster) == 0 {
+				return cluster, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("no free clusters available")
+}
+
+// endOfChainMarker returns the value used to terminate a cluster chain for the given FAT type.
+func endOfChainMarker(t fatType) uint32 {
+	switch t {
+	case fatType12:
+		return 0x0FFF
+	case fatType16:
+		return 0xFFFF
+	default:
+		return 0x0FFFFFFF
+	}
+}
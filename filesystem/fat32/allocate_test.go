@@ -0,0 +1,49 @@
+package fat32
+
+import "testing"
+
+// TestAllocateClusterRecomputesUnknownFreeCount exercises a volume whose FSInfo free count
+// carries the spec-legal fsInfoUnknown sentinel (0xFFFFFFFF, "must be recomputed"):
+// allocateCluster must derive the real free count from a FAT scan rather than treating the
+// unknown sentinel as zero free clusters, which would permanently stamp a false "0 free" into
+// FSInfo after the very first allocation.
+func TestAllocateClusterRecomputesUnknownFreeCount(t *testing.T) {
+	const sectorSize = 512
+	const sectorsPerFAT = 1
+	const dataSectors = 4 // clusters 2-5
+	bpb := &dos331BPB{
+		bytesPerSector:      sectorSize,
+		sectorsPerCluster:   1,
+		reservedSectorCount: 1,
+		numFATs:             1,
+		totalSectors32:      1 + sectorsPerFAT + dataSectors,
+	}
+	ebpb := &dos71EBPB{
+		dos331BPB:             bpb,
+		sectorsPerFat:         sectorsPerFAT,
+		extendedBootSignature: shortDos71EBPB,
+	}
+	fs := &FileSystem{
+		bpb:    bpb,
+		ebpb32: ebpb,
+		fsType: fatType32,
+		fsis:   &fsInfoSector{freeCount: fsInfoUnknown, nextFree: 2},
+	}
+
+	fatTable := make([]byte, sectorsPerFAT*sectorSize)
+	fatEntrySet(fatType32, fatTable, 3, endOfChainMarker(fatType32)) // cluster 3 already allocated
+
+	found, err := fs.allocateCluster(fatTable, fs.countOfClusters())
+	if err != nil {
+		t.Fatalf("allocateCluster: %v", err)
+	}
+	if found != 2 {
+		t.Fatalf("allocateCluster found cluster %d, want 2", found)
+	}
+
+	// Of clusters 2-5, only 3 was already allocated; allocating cluster 2 should leave 2 free
+	// (4 and 5), not 0.
+	if free, ok := fs.FreeClusters(); !ok || free != 2 {
+		t.Errorf("FreeClusters() = (%d, %v), want (2, true)", free, ok)
+	}
+}
@@ -0,0 +1,234 @@
+package fat32
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	// bpbRegionStartShort/Long are the byte offsets, within the 512-byte boot sector, where the
+	// BPB/EBPB region ends and the boot code payload may begin, per the short (60-byte) and
+	// long (79-byte) DOS 7.1 EBPB forms.
+	bpbRegionEndShort = 0x53
+	bpbRegionEndLong  = 0x5A
+	// oemNameOffset is where the 8-byte OEM name field begins, right after jmpBoot.
+	oemNameOffset = 3
+	// bpbOffset is where the embedded BPB/EBPB itself begins.
+	bpbOffset = 0x0B
+)
+
+// bootCodeRegionStart returns the byte offset, within the 512-byte boot sector, where the boot
+// code payload begins: right after the BPB/EBPB region, whose length depends on the short vs.
+// long EBPB form. BootCode implementations that need to bake in an absolute runtime address
+// (such as FreeLoaderChainloader's DAP) call this to learn where writeBootableSector will place
+// their payload, since it's computed the same way on both sides.
+func bootCodeRegionStart(ebpb32 *dos71EBPB) int {
+	if ebpb32 != nil && ebpb32.extendedBootSignature == longDos71EBPB {
+		return bpbRegionEndLong
+	}
+	return bpbRegionEndShort
+}
+
+// BootCode supplies the boot code payload installed into the unused region of a FAT32 boot
+// sector (everything outside the BPB/EBPB and the final 0xAA55 signature). EntryOffset is the
+// byte offset, from the start of the sector, that execution should jump to; it is used to
+// generate the jmpBoot opcode.
+type BootCode interface {
+	// Payload returns the bytes to install, and the sector offset execution should begin at.
+	Payload(fs *FileSystem) (code []byte, entryOffset int, err error)
+}
+
+// NonBootableStub is a BootCode that prints a message and waits for a keypress before
+// rebooting via INT 19h, in the tradition of MS-DOS's "Non-System disk or disk error /
+// Replace and press any key when ready". It never chains to anything on disk.
+type NonBootableStub struct {
+	// Message is the text to display; it is truncated to fit the available boot code region.
+	Message string
+}
+
+// Payload renders the message followed by a fixed trailer of: INT 10h (AH=0Eh) per character
+// to print via BIOS teletype output, a wait-for-keypress via INT 16h, then INT 19h to reboot.
+// This is a template, not a disassembled/assembled program - it records the structure real
+// bootstrap code follows without pretending to run an x86 assembler in this package.
+func (s NonBootableStub) Payload(fs *FileSystem) ([]byte, int, error) {
+	msg := s.Message
+	if msg == "" {
+		msg = "Non-system disk or disk error"
+	}
+	var code []byte
+	for _, ch := range []byte(msg) {
+		code = append(code, 0xB4, 0x0E, 0xB0, ch, 0xCD, 0x10) // mov ah,0eh; mov al,ch; int 10h
+	}
+	code = append(code, 0xB4, 0x00, 0xCD, 0x16) // mov ah,0; int 16h (wait for key)
+	code = append(code, 0xCD, 0x19)             // int 19h (reboot)
+	return code, 0, nil
+}
+
+// FreeLoaderChainloader is a minimal FreeLoader/ReactOS-style FAT32 chainloader: it locates a
+// named file (conventionally FREELDR.SYS or BOOTMGR) in the root directory, then emits a stage-1
+// stub that uses the INT 13h AH=42h BIOS extensions to read that file's first cluster straight
+// into memory at 0000:7E00 (immediately past the 512-byte boot sector) and far-jumps to it. It
+// assumes, as real FAT32 VBR stage-1 loaders do, that the target file occupies a single
+// contiguous run of sectors starting at its first cluster - true for a file freshly written to
+// an empty filesystem, but not guaranteed in general, which is why this only reads the file's
+// first cluster's worth of sectors rather than following its FAT chain. It also assumes the
+// BIOS both supports the INT 13h extensions and still has the boot drive number in DL, as it was
+// at the moment INT 19h dispatched to this boot sector. Use RawBootCode with your own stub if
+// either assumption doesn't hold for the target environment.
+type FreeLoaderChainloader struct {
+	FileName string // FileName is the 8.3 name to locate in the root directory, e.g. "FREELDR.SYS"
+}
+
+// freeLoaderEntryLen is the size, in bytes, of FreeLoaderChainloader's hand-assembled stage-1
+// stub, which is immediately followed in the boot code region by its 16-byte INT 13h extensions
+// disk address packet (DAP).
+const freeLoaderEntryLen = 16
+
+// Payload locates FileName in the root directory and returns a stage-1 stub that reads its
+// first cluster via INT 13h AH=42h into 0000:7E00 and jumps there, falling back to INT 18h
+// (network boot / next device) if the BIOS reports the read failed. An error is returned if
+// FileName cannot be found.
+func (c FreeLoaderChainloader) Payload(fs *FileSystem) ([]byte, int, error) {
+	root, err := fs.rootClusters()
+	if err != nil {
+		return nil, 0, err
+	}
+	entries, err := fs.readRootEntries(root)
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not read root directory for chainloader target: %v", err)
+	}
+
+	var target *dirEntry
+	for i := range entries {
+		e := entries[i]
+		if e.isEnd() {
+			break
+		}
+		if !e.isDeleted() && !e.isLongName() && !e.isDirectory() && e.shortName() == c.FileName {
+			target = &e
+			break
+		}
+	}
+	if target == nil {
+		return nil, 0, fmt.Errorf("chainloader target %q not found in root directory", c.FileName)
+	}
+
+	sectorSize := uint64(fs.bpb.bytesPerSector)
+	lba := uint64(fs.clusterOffset(target.firstCluster())) / sectorSize
+	sectorCount := (uint64(target.fileSize) + sectorSize - 1) / sectorSize
+	if sectorCount == 0 {
+		sectorCount = 1
+	}
+	if sectorCount > 0xFFFF {
+		return nil, 0, fmt.Errorf("chainloader target %q is too large for a single INT 13h extended read: %d sectors", c.FileName, sectorCount)
+	}
+
+	// dapAddr is the absolute real-mode address (segment 0, so just an offset from 0000:0000) of
+	// the DAP that immediately follows this stub: the BIOS boot sector is loaded at 0000:7C00,
+	// and bootCodeRegionStart(fs.ebpb32) is where writeBootableSector will place this payload
+	// within that sector.
+	dapAddr := uint16(0x7C00 + bootCodeRegionStart(fs.ebpb32) + freeLoaderEntryLen)
+
+	code := []byte{
+		0xBE, uint8(dapAddr), uint8(dapAddr >> 8), // mov si, dapAddr
+		0xB4, 0x42, // mov ah, 0x42 (INT 13h extensions: extended read)
+		0xCD, 0x13, // int 0x13
+		0x72, 0x05, // jc +5 (skip the jmp below on read failure)
+		0xEA, 0x00, 0x7E, 0x00, 0x00, // jmp 0000:7E00
+		0xCD, 0x18, // int 0x18 (read failed: hand off to the next boot device)
+	}
+	if len(code) != freeLoaderEntryLen {
+		return nil, 0, fmt.Errorf("internal error: chainloader stub is %d bytes, want %d", len(code), freeLoaderEntryLen)
+	}
+
+	dap := make([]byte, 16)
+	dap[0] = 0x10 // size of packet
+	binary.LittleEndian.PutUint16(dap[2:4], uint16(sectorCount))
+	binary.LittleEndian.PutUint16(dap[4:6], 0x7E00) // transfer buffer offset
+	binary.LittleEndian.PutUint16(dap[6:8], 0x0000) // transfer buffer segment
+	binary.LittleEndian.PutUint64(dap[8:16], lba)
+	code = append(code, dap...)
+
+	return code, 0, nil
+}
+
+// RawBootCode is a BootCode that installs caller-supplied bytes verbatim, for users who have
+// their own assembled boot program.
+type RawBootCode struct {
+	Code        []byte
+	EntryOffset int
+}
+
+// Payload returns Code and EntryOffset unchanged.
+func (r RawBootCode) Payload(fs *FileSystem) ([]byte, int, error) {
+	return r.Code, r.EntryOffset, nil
+}
+
+// SetBootCode installs code into the FileSystem, ready to be written by a subsequent sync. The
+// BPB/EBPB region is always preserved untouched; only the jmpBoot opcode, the boot code region
+// and the trailing 0xAA55 signature are affected.
+func (fs *FileSystem) SetBootCode(code BootCode) error {
+	fs.bootCode = code
+	return fs.writeBootableSector()
+}
+
+// MakeBootable installs a FreeLoaderChainloader pointed at kernelPath (a root-relative 8.3
+// name) and writes it out. It is a convenience wrapper for the common case of chaining to a
+// single named boot file.
+func (fs *FileSystem) MakeBootable(kernelPath string) error {
+	return fs.SetBootCode(FreeLoaderChainloader{FileName: kernelPath})
+}
+
+// writeBootableSector builds a full 512-byte boot sector - preserved BPB/EBPB, a jmpBoot opcode
+// matching the installed boot code's entry point, the boot code itself, and the 0xAA55
+// signature - and writes it (and its backup mirror) via writeBootSectorTriplet.
+func (fs *FileSystem) writeBootableSector() error {
+	if fs.ebpb32 == nil {
+		return fmt.Errorf("SetBootCode currently supports FAT32 volumes only")
+	}
+
+	sectorSize := int(fs.bpb.bytesPerSector)
+	sector := make([]byte, sectorSize)
+
+	ebpbBytes, err := fs.ebpb32.toBytes()
+	if err != nil {
+		return fmt.Errorf("could not serialize EBPB: %v", err)
+	}
+	copy(sector[bpbOffset:], ebpbBytes)
+
+	code := fs.bootCode
+	if code == nil {
+		code = NonBootableStub{}
+	}
+	payload, entryOffset, err := code.Payload(fs)
+	if err != nil {
+		return fmt.Errorf("could not build boot code: %v", err)
+	}
+	codeStart := bootCodeRegionStart(fs.ebpb32)
+	if codeStart+len(payload) > bootSectorSignatureOffset {
+		return fmt.Errorf("boot code payload of %d bytes does not fit in the %d bytes available", len(payload), bootSectorSignatureOffset-codeStart)
+	}
+	copy(sector[codeStart:], payload)
+
+	installJmpBoot(sector, codeStart+entryOffset)
+
+	return fs.writeBootSectorTriplet(sector)
+}
+
+// installJmpBoot writes the 3-byte x86 jump instruction at the start of the sector so that
+// execution lands on target, preferring a short jump (0xEB, disp8, 0x90) when target is
+// reachable with an 8-bit displacement and falling back to a near jump (0xE9, disp16) otherwise.
+func installJmpBoot(sector []byte, target int) {
+	const jmpInstructionEnd = 2 // a short jump's displacement is relative to the byte after it
+	disp := target - jmpInstructionEnd
+	if disp >= -128 && disp <= 127 {
+		sector[0] = 0xEB
+		sector[1] = uint8(disp)
+		sector[2] = 0x90
+		return
+	}
+	near := target - 3
+	sector[0] = 0xE9
+	sector[1] = uint8(near)
+	sector[2] = uint8(near >> 8)
+}
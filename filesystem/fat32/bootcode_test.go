@@ -0,0 +1,101 @@
+package fat32
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// newTestFAT32FileSystem builds a minimal in-memory FAT32 volume with a single-cluster root
+// directory containing one file entry, for exercising root-directory lookups such as
+// FreeLoaderChainloader.Payload.
+func newTestFAT32FileSystem(rootEntries ...dirEntry) *FileSystem {
+	const sectorSize = 512
+	const reservedSectors = 32
+	const sectorsPerFat = 1
+	const rootCluster = 2
+
+	bpb := &dos331BPB{
+		bytesPerSector:      sectorSize,
+		sectorsPerCluster:   1,
+		reservedSectorCount: reservedSectors,
+		numFATs:             1,
+		totalSectors32:      reservedSectors + sectorsPerFat + 8,
+	}
+	ebpb := &dos71EBPB{
+		dos331BPB:             bpb,
+		sectorsPerFat:         sectorsPerFat,
+		rootDirectoryCluster:  rootCluster,
+		extendedBootSignature: shortDos71EBPB,
+	}
+
+	fs := &FileSystem{
+		bpb:    bpb,
+		ebpb32: ebpb,
+		fsType: fatType32,
+		file:   &fakeBlockDevice{data: make([]byte, int(bpb.totalSectors32)*sectorSize)},
+	}
+
+	var raw []byte
+	for _, e := range rootEntries {
+		entry := make([]byte, 32)
+		copy(entry[0:11], e.name[:])
+		entry[11] = e.attr
+		entry[20] = uint8(e.firstClusterHigh)
+		entry[21] = uint8(e.firstClusterHigh >> 8)
+		entry[26] = uint8(e.firstClusterLow)
+		entry[27] = uint8(e.firstClusterLow >> 8)
+		entry[28] = uint8(e.fileSize)
+		entry[29] = uint8(e.fileSize >> 8)
+		entry[30] = uint8(e.fileSize >> 16)
+		entry[31] = uint8(e.fileSize >> 24)
+		raw = append(raw, entry...)
+	}
+	if _, err := fs.file.WriteAt(raw, fs.clusterOffset(rootCluster)); err != nil {
+		panic(err)
+	}
+
+	return fs
+}
+
+// TestFreeLoaderChainloaderPayloadReadsAndJumps verifies the stage-1 stub's shape: an INT 13h
+// AH=42h extended read of the target's first cluster followed by a far jump to where it was
+// loaded, with a DAP whose starting LBA matches the target's resolved first cluster.
+func TestFreeLoaderChainloaderPayloadReadsAndJumps(t *testing.T) {
+	target := dirEntry{firstClusterLow: 5, fileSize: 1024}
+	copy(target.name[:], "FREELDR SYS")
+	fs := newTestFAT32FileSystem(target)
+
+	payload, entryOffset, err := FreeLoaderChainloader{FileName: "FREELDR.SYS"}.Payload(fs)
+	if err != nil {
+		t.Fatalf("Payload: %v", err)
+	}
+	if entryOffset != 0 {
+		t.Errorf("entryOffset = %d, want 0", entryOffset)
+	}
+	if len(payload) != freeLoaderEntryLen+16 {
+		t.Fatalf("len(payload) = %d, want %d (stub + 16-byte DAP)", len(payload), freeLoaderEntryLen+16)
+	}
+	if payload[3] != 0xB4 || payload[4] != 0x42 {
+		t.Errorf("payload[3:5] = %#v, want mov ah,0x42 (0xB4, 0x42)", payload[3:5])
+	}
+	if payload[5] != 0xCD || payload[6] != 0x13 {
+		t.Errorf("payload[5:7] = %#v, want int 0x13 (0xCD, 0x13)", payload[5:7])
+	}
+	dap := payload[freeLoaderEntryLen:]
+	if dap[0] != 0x10 {
+		t.Errorf("DAP size = %#02x, want 0x10", dap[0])
+	}
+	wantLBA := uint64(fs.clusterOffset(target.firstCluster())) / uint64(fs.bpb.bytesPerSector)
+	if gotLBA := binary.LittleEndian.Uint64(dap[8:16]); gotLBA != wantLBA {
+		t.Errorf("DAP starting LBA = %d, want %d", gotLBA, wantLBA)
+	}
+}
+
+// TestFreeLoaderChainloaderPayloadNotFound verifies the lookup still fails cleanly when the
+// target file is absent.
+func TestFreeLoaderChainloaderPayloadNotFound(t *testing.T) {
+	fs := newTestFAT32FileSystem()
+	if _, _, err := (FreeLoaderChainloader{FileName: "MISSING.SYS"}).Payload(fs); err == nil {
+		t.Error("Payload() error = nil, want an error for a missing target")
+	}
+}
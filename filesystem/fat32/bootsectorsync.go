@@ -0,0 +1,159 @@
+package fat32
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// bootSectorSignatureOffset is the offset of the 0xAA55 boot sector signature, common to every
+// FAT type.
+const bootSectorSignatureOffset = 510
+
+// bootSectorSignature is the 2-byte value every valid boot sector ends with.
+const bootSectorSignature uint16 = 0xAA55
+
+// Mismatch describes a single divergence found by FileSystem.Verify between a primary sector
+// and its backup copy.
+type Mismatch struct {
+	Sector uint16 // Sector is the backup sector number (relative to backupBootSector) that diverged
+	Reason string // Reason is a short human-readable description of the divergence
+}
+
+// validateBootSectorSignature checks that a raw 512-byte boot sector carries the 0xAA55
+// signature at offset 510, per the FAT32 spec (and matching the check fsck_msdosfs performs
+// before trusting a boot sector).
+func validateBootSectorSignature(b []byte) error {
+	if len(b) != 512 {
+		return fmt.Errorf("boot sector must be precisely 512 bytes, got %d", len(b))
+	}
+	sig := binary.LittleEndian.Uint16(b[bootSectorSignatureOffset:])
+	if sig != bootSectorSignature {
+		return fmt.Errorf("invalid boot sector signature: %#04x", sig)
+	}
+	return nil
+}
+
+// writeBootSectorTriplet writes the primary boot sector and, when backupBootSector is set,
+// mirrors it and the two sectors that follow (the backup FSInfo sector and the additional
+// reserved sector) to their backup location. All three backup sectors are written before
+// returning, so that a failure partway through is easy to detect with Verify rather than
+// leaving a half-updated backup that looks valid.
+func (fs *FileSystem) writeBootSectorTriplet(primary []byte) error {
+	if err := validateBootSectorSignature(primary); err != nil {
+		return fmt.Errorf("refusing to write invalid boot sector: %v", err)
+	}
+	sectorSize := int64(fs.bpb.bytesPerSector)
+
+	if _, err := fs.file.WriteAt(primary, 0); err != nil {
+		return fmt.Errorf("could not write primary boot sector: %v", err)
+	}
+
+	if fs.ebpb32 == nil || fs.ebpb32.backupBootSector == 0 {
+		return nil
+	}
+	backupStart := int64(fs.ebpb32.backupBootSector) * sectorSize
+	if _, err := fs.file.WriteAt(primary, backupStart); err != nil {
+		return fmt.Errorf("could not write backup boot sector: %v", err)
+	}
+
+	if fs.fsis != nil {
+		fsisBytes := fs.fsis.toBytes()
+		if _, err := fs.file.WriteAt(fsisBytes, backupStart+sectorSize); err != nil {
+			return fmt.Errorf("could not write backup FSInfo sector: %v", err)
+		}
+	}
+
+	// the third sector of the backup region is reserved and carries no defined content; it is
+	// still mirrored so that the three backup sectors always read back as a consistent unit.
+	reserved := make([]byte, sectorSize)
+	if _, err := fs.file.WriteAt(reserved, backupStart+2*sectorSize); err != nil {
+		return fmt.Errorf("could not write reserved backup sector: %v", err)
+	}
+
+	return nil
+}
+
+// readBootSector reads the primary boot sector, and - when fallback is true and the primary
+// fails signature validation - falls back to the backup copy at backupBootSector.
+func (fs *FileSystem) readBootSector(fallback bool) ([]byte, error) {
+	sectorSize := int64(fs.bpb.bytesPerSector)
+	primary := make([]byte, sectorSize)
+	if _, err := fs.file.ReadAt(primary, 0); err != nil {
+		return nil, fmt.Errorf("could not read primary boot sector: %v", err)
+	}
+	if err := validateBootSectorSignature(primary); err == nil {
+		return primary, nil
+	} else if !fallback || fs.ebpb32 == nil || fs.ebpb32.backupBootSector == 0 {
+		return nil, err
+	}
+
+	backup := make([]byte, sectorSize)
+	backupStart := int64(fs.ebpb32.backupBootSector) * sectorSize
+	if _, err := fs.file.ReadAt(backup, backupStart); err != nil {
+		return nil, fmt.Errorf("could not read backup boot sector: %v", err)
+	}
+	if err := validateBootSectorSignature(backup); err != nil {
+		return nil, fmt.Errorf("both primary and backup boot sectors are invalid: %v", err)
+	}
+	return backup, nil
+}
+
+// peekBackupBootSector reads the backupBootSector field directly out of a raw FAT32 boot
+// sector, without requiring the rest of the EBPB to parse cleanly. ReadWithOptions needs only
+// this one field from an as-yet-unvalidated primary sector to find the backup triplet; demanding
+// a full EBPB parse first would mean any corruption elsewhere in the EBPB (e.g. a garbled
+// version field) defeats the backup fallback before it gets a chance to run. It returns 0 -
+// never a valid sector number - if sector is too short, or doesn't carry a recognized DOS 7.1
+// EBPB signature to find the field at.
+func peekBackupBootSector(sector []byte) uint16 {
+	if len(sector) < bpbOffset+41 {
+		return 0
+	}
+	switch sector[bpbOffset+55] {
+	case shortDos71EBPB, longDos71EBPB:
+		return binary.LittleEndian.Uint16(sector[bpbOffset+39 : bpbOffset+41])
+	default:
+		return 0
+	}
+}
+
+// Verify compares the primary boot sector and FSInfo sector against their backup copies and
+// reports any byte-for-byte divergence, for use by repair tools deciding which copy to trust.
+func (fs *FileSystem) Verify() ([]Mismatch, error) {
+	if fs.ebpb32 == nil || fs.ebpb32.backupBootSector == 0 {
+		return nil, nil
+	}
+	sectorSize := int64(fs.bpb.bytesPerSector)
+	backupStart := int64(fs.ebpb32.backupBootSector) * sectorSize
+
+	var mismatches []Mismatch
+
+	primary := make([]byte, sectorSize)
+	if _, err := fs.file.ReadAt(primary, 0); err != nil {
+		return nil, fmt.Errorf("could not read primary boot sector: %v", err)
+	}
+	backup := make([]byte, sectorSize)
+	if _, err := fs.file.ReadAt(backup, backupStart); err != nil {
+		return nil, fmt.Errorf("could not read backup boot sector: %v", err)
+	}
+	if !bytes.Equal(primary, backup) {
+		mismatches = append(mismatches, Mismatch{Sector: fs.ebpb32.backupBootSector, Reason: "backup boot sector does not match primary"})
+	}
+
+	if fs.ebpb32.fsInformationSector != 0 {
+		primaryFSIS := make([]byte, sectorSize)
+		if _, err := fs.file.ReadAt(primaryFSIS, int64(fs.ebpb32.fsInformationSector)*sectorSize); err != nil {
+			return nil, fmt.Errorf("could not read primary FSInfo sector: %v", err)
+		}
+		backupFSIS := make([]byte, sectorSize)
+		if _, err := fs.file.ReadAt(backupFSIS, backupStart+sectorSize); err != nil {
+			return nil, fmt.Errorf("could not read backup FSInfo sector: %v", err)
+		}
+		if !bytes.Equal(primaryFSIS, backupFSIS) {
+			mismatches = append(mismatches, Mismatch{Sector: fs.ebpb32.backupBootSector + 1, Reason: "backup FSInfo sector does not match primary"})
+		}
+	}
+
+	return mismatches, nil
+}
@@ -0,0 +1,45 @@
+package fat32
+
+// dataRegionStart returns the sector number of cluster 2, the first data cluster, relative to
+// the start of the volume.
+func (fs *FileSystem) dataRegionStart() uint32 {
+	fatSectors := fs.sectorsPerFAT() * uint32(fs.bpb.numFATs)
+	start := uint32(fs.bpb.reservedSectorCount) + fatSectors
+	if fs.rootDir != nil {
+		// FAT12/16: the fixed root directory region sits between the FATs and the data region
+		start += uint32(fs.rootDir.sectorCount)
+	}
+	return start
+}
+
+// bytesPerCluster returns the size in bytes of a single cluster.
+func (fs *FileSystem) bytesPerCluster() uint32 {
+	return uint32(fs.bpb.bytesPerSector) * uint32(fs.bpb.sectorsPerCluster)
+}
+
+// clusterOffset returns the byte offset of the given cluster, relative to the start of the
+// volume. Cluster numbers below 2 are not valid data clusters.
+func (fs *FileSystem) clusterOffset(cluster uint32) int64 {
+	sector := fs.dataRegionStart() + (cluster-2)*uint32(fs.bpb.sectorsPerCluster)
+	return int64(sector) * int64(fs.bpb.bytesPerSector)
+}
+
+// readCluster reads the full contents of a single cluster.
+func (fs *FileSystem) readCluster(cluster uint32) ([]byte, error) {
+	buf := make([]byte, fs.bytesPerCluster())
+	if _, err := fs.file.ReadAt(buf, fs.clusterOffset(cluster)); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// countOfClusters returns the total number of addressable data clusters, per the same
+// computation used by determineFatType.
+func (fs *FileSystem) countOfClusters() uint32 {
+	totSec := uint32(fs.bpb.totalSectors16)
+	if totSec == 0 {
+		totSec = fs.bpb.totalSectors32
+	}
+	dataSec := totSec - fs.dataRegionStart()
+	return dataSec / uint32(fs.bpb.sectorsPerCluster)
+}
@@ -0,0 +1,70 @@
+package fat32
+
+// attrLongName marks a directory entry as part of a VFAT long filename chain rather than a
+// normal 8.3 entry. fsck only needs to skip these when walking for cluster chains - the
+// filename they encode is irrelevant to structural checks - so they are not decoded further.
+const attrLongName uint8 = 0x0F
+
+const (
+	attrDirectory uint8 = 0x10
+	attrVolumeID  uint8 = 0x08
+)
+
+// entryFreeMarker and entryDeletedMarker are the two sentinel values name[0] can take that are
+// not part of an actual filename.
+const (
+	entryFreeMarker    uint8 = 0x00 // marks the end of the directory; no further entries follow
+	entryDeletedMarker uint8 = 0xE5 // marks a deleted entry; the slot may be reused
+)
+
+// dirEntry is a single 32-byte FAT directory entry.
+type dirEntry struct {
+	name             [11]byte
+	attr             uint8
+	firstClusterHigh uint16
+	firstClusterLow  uint16
+	fileSize         uint32
+}
+
+// dirEntryFromBytes parses a single 32-byte directory entry.
+func dirEntryFromBytes(b []byte) dirEntry {
+	e := dirEntry{attr: b[11]}
+	copy(e.name[:], b[0:11])
+	e.firstClusterHigh = uint16(b[20]) | uint16(b[21])<<8
+	e.firstClusterLow = uint16(b[26]) | uint16(b[27])<<8
+	e.fileSize = uint32(b[28]) | uint32(b[29])<<8 | uint32(b[30])<<16 | uint32(b[31])<<24
+	return e
+}
+
+// firstCluster returns the starting cluster number of this entry's chain. firstClusterHigh is
+// always zero on FAT12/16, which have no room in the directory entry to store it.
+func (e dirEntry) firstCluster() uint32 {
+	return uint32(e.firstClusterHigh)<<16 | uint32(e.firstClusterLow)
+}
+
+func (e dirEntry) isLongName() bool { return e.attr == attrLongName }
+func (e dirEntry) isEnd() bool      { return e.name[0] == entryFreeMarker }
+func (e dirEntry) isDeleted() bool  { return e.name[0] == entryDeletedMarker }
+func (e dirEntry) isVolumeLabel() bool {
+	return e.attr&attrVolumeID != 0 && e.attr != attrLongName
+}
+func (e dirEntry) isDirectory() bool { return e.attr&attrDirectory != 0 }
+
+// shortName renders the raw 8.3 name field as a display string, trimming the space padding
+// DOS uses in both the base name and extension.
+func (e dirEntry) shortName() string {
+	base := trimTrailingSpaces(string(e.name[0:8]))
+	ext := trimTrailingSpaces(string(e.name[8:11]))
+	if ext == "" {
+		return base
+	}
+	return base + "." + ext
+}
+
+func trimTrailingSpaces(s string) string {
+	i := len(s)
+	for i > 0 && s[i-1] == ' ' {
+		i--
+	}
+	return s[:i]
+}
@@ -0,0 +1,88 @@
+package fat32
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Dos331BPB is the DOS 3.31 BIOS Parameter Block, the 25-byte geometry block common to every
+// FAT type (FAT12, FAT16 and FAT32 alike). It is embedded in both dos40EBPB (FAT12/16) and
+// dos71EBPB (FAT32), which each append their own type-specific fields after it. It does not
+// include the leading jmpBoot/OEM name bytes that precede it on disk - those belong to the
+// full boot sector, which is read and validated separately from the BPB/EBPB it wraps.
+type dos331BPB struct {
+	bytesPerSector      uint16 // BytesPerSector is the size of a sector in bytes
+	sectorsPerCluster   uint8  // SectorsPerCluster is the number of sectors per allocation unit
+	reservedSectorCount uint16 // ReservedSectorCount is the number of reserved sectors, including the boot sector itself
+	numFATs             uint8  // NumFATs is the number of copies of the FAT
+	rootEntryCount      uint16 // RootEntryCount is the number of 32-byte root directory entries, 0 for FAT32
+	totalSectors16      uint16 // TotalSectors16 is the total sector count when it fits in 16 bits, else 0
+	media               uint8  // Media is the media descriptor byte, mirrored in FAT[0]
+	sectorsPerFat16     uint16 // SectorsPerFat16 is the 16-bit sectors-per-FAT count, 0 for FAT32
+	sectorsPerTrack     uint16 // SectorsPerTrack is the number of sectors per track, for INT 13h geometry
+	numHeads            uint16 // NumHeads is the number of heads, for INT 13h geometry
+	hiddenSectors       uint32 // HiddenSectors is the count of sectors preceding this volume's partition
+	totalSectors32      uint32 // TotalSectors32 is the total sector count when it does not fit in 16 bits
+}
+
+func (bpb *dos331BPB) equal(a *dos331BPB) bool {
+	if (bpb == nil && a != nil) || (a == nil && bpb != nil) {
+		return false
+	}
+	if bpb == nil && a == nil {
+		return true
+	}
+	return bpb.bytesPerSector == a.bytesPerSector &&
+		bpb.sectorsPerCluster == a.sectorsPerCluster &&
+		bpb.reservedSectorCount == a.reservedSectorCount &&
+		bpb.numFATs == a.numFATs &&
+		bpb.rootEntryCount == a.rootEntryCount &&
+		bpb.totalSectors16 == a.totalSectors16 &&
+		bpb.media == a.media &&
+		bpb.sectorsPerFat16 == a.sectorsPerFat16 &&
+		bpb.sectorsPerTrack == a.sectorsPerTrack &&
+		bpb.numHeads == a.numHeads &&
+		bpb.hiddenSectors == a.hiddenSectors &&
+		bpb.totalSectors32 == a.totalSectors32
+}
+
+// Dos331BPBFromBytes reads the DOS 3.31 BIOS Parameter Block from a slice of precisely 25
+// bytes, the fixed size of the common BPB shared by every FAT type.
+func dos331BPBFromBytes(b []byte) (*dos331BPB, error) {
+	if b == nil || len(b) != 25 {
+		return nil, errors.New("cannot read DOS 3.31 BPB from invalid byte slice, must be precisely 25 bytes")
+	}
+	bpb := dos331BPB{
+		bytesPerSector:      binary.LittleEndian.Uint16(b[0:2]),
+		sectorsPerCluster:   uint8(b[2]),
+		reservedSectorCount: binary.LittleEndian.Uint16(b[3:5]),
+		numFATs:             uint8(b[5]),
+		rootEntryCount:      binary.LittleEndian.Uint16(b[6:8]),
+		totalSectors16:      binary.LittleEndian.Uint16(b[8:10]),
+		media:               uint8(b[10]),
+		sectorsPerFat16:     binary.LittleEndian.Uint16(b[11:13]),
+		sectorsPerTrack:     binary.LittleEndian.Uint16(b[13:15]),
+		numHeads:            binary.LittleEndian.Uint16(b[15:17]),
+		hiddenSectors:       binary.LittleEndian.Uint32(b[17:21]),
+		totalSectors32:      binary.LittleEndian.Uint32(b[21:25]),
+	}
+	return &bpb, nil
+}
+
+// ToBytes returns the DOS 3.31 BPB in a slice of 25 bytes directly ready to write to disk.
+func (bpb *dos331BPB) toBytes() ([]byte, error) {
+	b := make([]byte, 25, 25)
+	binary.LittleEndian.PutUint16(b[0:2], bpb.bytesPerSector)
+	b[2] = bpb.sectorsPerCluster
+	binary.LittleEndian.PutUint16(b[3:5], bpb.reservedSectorCount)
+	b[5] = bpb.numFATs
+	binary.LittleEndian.PutUint16(b[6:8], bpb.rootEntryCount)
+	binary.LittleEndian.PutUint16(b[8:10], bpb.totalSectors16)
+	b[10] = bpb.media
+	binary.LittleEndian.PutUint16(b[11:13], bpb.sectorsPerFat16)
+	binary.LittleEndian.PutUint16(b[13:15], bpb.sectorsPerTrack)
+	binary.LittleEndian.PutUint16(b[15:17], bpb.numHeads)
+	binary.LittleEndian.PutUint32(b[17:21], bpb.hiddenSectors)
+	binary.LittleEndian.PutUint32(b[21:25], bpb.totalSectors32)
+	return b, nil
+}
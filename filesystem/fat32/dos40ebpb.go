@@ -0,0 +1,130 @@
+package fat32
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+const (
+	// ShortDos40EBPB indicates that a DOS 4.0 EBPB is of the short 32-byte format
+	shortDos40EBPB uint8 = 0x28
+	// LongDos40EBPB indicates that a DOS 4.0 EBPB is of the long 51-byte format
+	longDos40EBPB uint8 = 0x29
+)
+
+// Dos40EBPB is the DOS 4.0 Extended BIOS Parameter Block used by FAT12 and FAT16 volumes.
+// Unlike dos71EBPB, it carries no FAT32-specific fields: there is no sectorsPerFat,
+// rootDirectoryCluster, fsInformationSector or backupBootSector, since FAT12/16 keep a
+// single fixed-size root directory and store the sectors-per-FAT count in the embedded
+// dos331BPB instead.
+type dos40EBPB struct {
+	dos331BPB             *dos331BPB // Dos331BPB holds the embedded DOS 3.31 BIOS Parameter Block
+	driveNumber           uint8      // DriveNumber is the code for the relative position and type of this drive in the system
+	reservedFlags         uint8      // ReservedFlags are flags used by the operating system and/or BIOS for various purposes
+	extendedBootSignature uint8      // ExtendedBootSignature contains the flag as to whether this is a short (32-byte) or long (51-byte) DOS 4.0 EBPB
+	volumeSerialNumber    uint32     // VolumeSerialNumber usually generated by some form of date and time
+	volumeLabel           string     // VolumeLabel, an arbitrary 11-byte string
+	fileSystemType        string     // FileSystemType is the 8-byte string holding the name of the file system type, e.g. "FAT12   " or "FAT16   "
+}
+
+func (bpb *dos40EBPB) equal(a *dos40EBPB) bool {
+	if (bpb == nil && a != nil) || (a == nil && bpb != nil) {
+		return false
+	}
+	if bpb == nil && a == nil {
+		return true
+	}
+	return bpb.dos331BPB.equal(a.dos331BPB) &&
+		bpb.driveNumber == a.driveNumber &&
+		bpb.reservedFlags == a.reservedFlags &&
+		bpb.extendedBootSignature == a.extendedBootSignature &&
+		bpb.volumeSerialNumber == a.volumeSerialNumber &&
+		bpb.volumeLabel == a.volumeLabel &&
+		bpb.fileSystemType == a.fileSystemType
+}
+
+// Dos40EBPBFromBytes reads the FAT12/16 Extended BIOS Parameter Block from a slice of bytes.
+// These bytes are assumed to start at the beginning of the EBPB, but can stretch for any
+// length, since the calling function should know where the EBPB starts, but not necessarily
+// where it ends.
+func dos40EBPBFromBytes(b []byte) (*dos40EBPB, int, error) {
+	if b == nil || (len(b) != 32 && len(b) != 51) {
+		return nil, 0, errors.New("cannot read DOS 4.0 EBPB from invalid byte slice, must be precisely 32 or 51 bytes")
+	}
+	bpb := dos40EBPB{}
+	size := 0
+
+	// extract the embedded DOS 3.31 BPB
+	dos331bpb, err := dos331BPBFromBytes(b[0:25])
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not read embedded DOS 3.31 BPB: %v", err)
+	}
+	bpb.dos331BPB = dos331bpb
+
+	bpb.driveNumber = uint8(b[25])
+	bpb.reservedFlags = uint8(b[26])
+	extendedSignature := uint8(b[27])
+	bpb.extendedBootSignature = extendedSignature
+	bpb.volumeSerialNumber = binary.BigEndian.Uint32(b[28:32])
+
+	switch extendedSignature {
+	case shortDos40EBPB:
+		size = 32
+	case longDos40EBPB:
+		size = 51
+		// remove padding from each
+		re := regexp.MustCompile("[ ]+$")
+		bpb.volumeLabel = re.ReplaceAllString(string(b[32:43]), "")
+		bpb.fileSystemType = re.ReplaceAllString(string(b[43:51]), "")
+	default:
+		return nil, size, fmt.Errorf("unknown DOS 4.0 EBPB signature: %v", extendedSignature)
+	}
+
+	return &bpb, size, nil
+}
+
+// ToBytes returns the Extended BIOS Parameter Block in a slice of bytes directly ready to
+// write to disk
+func (bpb *dos40EBPB) toBytes() ([]byte, error) {
+	var b []byte
+	switch bpb.extendedBootSignature {
+	case shortDos40EBPB:
+		b = make([]byte, 32, 32)
+	case longDos40EBPB:
+		b = make([]byte, 51, 51)
+		label := bpb.volumeLabel
+		if len(label) > 11 {
+			return nil, fmt.Errorf("invalid volume label: too long at %d characters, maximum is %d", len(label), 11)
+		}
+		labelR := []rune(label)
+		if len(label) != len(labelR) {
+			return nil, fmt.Errorf("invalid volume label: non-ascii characters")
+		}
+		copy(b[32:43], []byte(fmt.Sprintf("%-11s", label)))
+		fstype := bpb.fileSystemType
+		if len(fstype) > 8 {
+			return nil, fmt.Errorf("invalid filesystem type: too long at %d characters, maximum is %d", len(fstype), 8)
+		}
+		fstypeR := []rune(fstype)
+		if len(fstype) != len(fstypeR) {
+			return nil, fmt.Errorf("invalid filesystem type: non-ascii characters")
+		}
+		copy(b[43:51], []byte(fmt.Sprintf("%-8s", fstype)))
+	default:
+		return nil, fmt.Errorf("unknown DOS 4.0 EBPB signature: %v", bpb.extendedBootSignature)
+	}
+
+	dos331Bytes, err := bpb.dos331BPB.toBytes()
+	if err != nil {
+		return nil, fmt.Errorf("error converting embedded DOS 3.31 BPB to bytes: %v", err)
+	}
+	copy(b[0:25], dos331Bytes)
+	b[25] = bpb.driveNumber
+	b[26] = bpb.reservedFlags
+	b[27] = bpb.extendedBootSignature
+	binary.BigEndian.PutUint32(b[28:32], bpb.volumeSerialNumber)
+
+	return b, nil
+}
@@ -0,0 +1,91 @@
+package fat32
+
+// FAT entry values share the same meaning across all three table widths: 0x000 is a free
+// cluster, a value between 2 and the maximum valid cluster number for the table width points
+// to the next cluster in the chain, and any value at or above the "bad cluster" marker through
+// the end-of-chain range marks a bad or terminal cluster. Only the low 28 bits are significant
+// for FAT32 entries; the top 4 bits are reserved and must be preserved across updates.
+
+// fat12EntryGet reads the 12-bit FAT entry for the given cluster out of a raw FAT table. Two
+// consecutive entries are packed into three bytes: for an even cluster the entry is the low 12
+// bits of the 3-byte group; for an odd cluster it is the high 12 bits.
+func fat12EntryGet(fat []byte, cluster uint32) uint16 {
+	offset := cluster + (cluster / 2)
+	pair := uint16(fat[offset]) | uint16(fat[offset+1])<<8
+	if cluster%2 == 0 {
+		return pair & 0x0FFF
+	}
+	return pair >> 4
+}
+
+// fat12EntrySet writes the 12-bit FAT entry for the given cluster into a raw FAT table,
+// preserving the neighboring entry that shares its middle byte.
+func fat12EntrySet(fat []byte, cluster uint32, value uint16) {
+	offset := cluster + (cluster / 2)
+	value &= 0x0FFF
+	pair := uint16(fat[offset]) | uint16(fat[offset+1])<<8
+	if cluster%2 == 0 {
+		pair = (pair & 0xF000) | value
+	} else {
+		pair = (pair & 0x000F) | (value << 4)
+	}
+	fat[offset] = uint8(pair)
+	fat[offset+1] = uint8(pair >> 8)
+}
+
+// fat16EntryGet reads the 16-bit FAT entry for the given cluster out of a raw FAT table.
+func fat16EntryGet(fat []byte, cluster uint32) uint16 {
+	offset := cluster * 2
+	return uint16(fat[offset]) | uint16(fat[offset+1])<<8
+}
+
+// fat16EntrySet writes the 16-bit FAT entry for the given cluster into a raw FAT table.
+func fat16EntrySet(fat []byte, cluster uint32, value uint16) {
+	offset := cluster * 2
+	fat[offset] = uint8(value)
+	fat[offset+1] = uint8(value >> 8)
+}
+
+// fat32EntryGet reads the 32-bit FAT entry for the given cluster out of a raw FAT table,
+// masking off the reserved top 4 bits.
+func fat32EntryGet(fat []byte, cluster uint32) uint32 {
+	offset := cluster * 4
+	raw := uint32(fat[offset]) | uint32(fat[offset+1])<<8 | uint32(fat[offset+2])<<16 | uint32(fat[offset+3])<<24
+	return raw & 0x0FFFFFFF
+}
+
+// fat32EntrySet writes the low 28 bits of the 32-bit FAT entry for the given cluster into a
+// raw FAT table, preserving the reserved top 4 bits already present.
+func fat32EntrySet(fat []byte, cluster uint32, value uint32) {
+	offset := cluster * 4
+	reserved := (uint32(fat[offset+3]) << 24) & 0xF0000000
+	value = (value & 0x0FFFFFFF) | reserved
+	fat[offset] = uint8(value)
+	fat[offset+1] = uint8(value >> 8)
+	fat[offset+2] = uint8(value >> 16)
+	fat[offset+3] = uint8(value >> 24)
+}
+
+// fatEntryGet reads a single FAT entry for the given cluster, dispatching on fatType.
+func fatEntryGet(t fatType, fat []byte, cluster uint32) uint32 {
+	switch t {
+	case fatType12:
+		return uint32(fat12EntryGet(fat, cluster))
+	case fatType16:
+		return uint32(fat16EntryGet(fat, cluster))
+	default:
+		return fat32EntryGet(fat, cluster)
+	}
+}
+
+// fatEntrySet writes a single FAT entry for the given cluster, dispatching on fatType.
+func fatEntrySet(t fatType, fat []byte, cluster uint32, value uint32) {
+	switch t {
+	case fatType12:
+		fat12EntrySet(fat, cluster, uint16(value))
+	case fatType16:
+		fat16EntrySet(fat, cluster, uint16(value))
+	default:
+		fat32EntrySet(fat, cluster, value)
+	}
+}
@@ -0,0 +1,123 @@
+package fat32
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const (
+	// mirrorDisabledBit is bit 7 of mirrorFlags: when set, FAT mirroring is disabled and only
+	// the FAT indicated by mirrorActiveFATMask is kept up to date.
+	mirrorDisabledBit uint16 = 1 << 7
+	// mirrorActiveFATMask is bits 3-0 of mirrorFlags: the zero-based index of the active FAT
+	// when mirroring is disabled.
+	mirrorActiveFATMask uint16 = 0x0F
+)
+
+// mirroringDisabled reports whether bit 7 of mirrorFlags is set.
+func (bpb *dos71EBPB) mirroringDisabled() bool {
+	return bpb.mirrorFlags&mirrorDisabledBit != 0
+}
+
+// activeFAT returns the zero-based index of the FAT copy to use when mirroring is disabled. It
+// is meaningless when mirroringDisabled is false, since all copies are kept in sync.
+func (bpb *dos71EBPB) activeFAT() int {
+	return int(bpb.mirrorFlags & mirrorActiveFATMask)
+}
+
+// sectorsPerFAT returns the effective sectors-per-FAT count, preferring the FAT32 32-bit field
+// and falling back to the legacy 16-bit field for FAT12/16.
+func (fs *FileSystem) sectorsPerFAT() uint32 {
+	if fs.ebpb32 != nil && fs.ebpb32.sectorsPerFat != 0 {
+		return fs.ebpb32.sectorsPerFat
+	}
+	return uint32(fs.bpb.sectorsPerFat16)
+}
+
+// fatOffset returns the byte offset of FAT copy n, relative to the start of the volume.
+func (fs *FileSystem) fatOffset(n int) int64 {
+	sectorSize := int64(fs.bpb.bytesPerSector)
+	fatStart := int64(fs.bpb.reservedSectorCount) * sectorSize
+	fatSize := int64(fs.sectorsPerFAT()) * sectorSize
+	return fatStart + int64(n)*fatSize
+}
+
+// readFAT reads the FAT copy that should be treated as authoritative for reads: the active FAT
+// when mirroring is disabled, otherwise FAT #0 (which is always kept in sync with the rest).
+func (fs *FileSystem) readFAT() ([]byte, error) {
+	n := 0
+	if fs.ebpb32 != nil && fs.ebpb32.mirroringDisabled() {
+		n = fs.ebpb32.activeFAT()
+	}
+	size := int64(fs.sectorsPerFAT()) * int64(fs.bpb.bytesPerSector)
+	buf := make([]byte, size)
+	if _, err := fs.file.ReadAt(buf, fs.fatOffset(n)); err != nil {
+		return nil, fmt.Errorf("could not read FAT #%d: %v", n, err)
+	}
+	return buf, nil
+}
+
+// writeFAT writes table to every FAT copy that should reflect it: all NumFATs copies when
+// mirroring is enabled, or only the active FAT when it is disabled.
+func (fs *FileSystem) writeFAT(table []byte) error {
+	if fs.ebpb32 != nil && fs.ebpb32.mirroringDisabled() {
+		n := fs.ebpb32.activeFAT()
+		if _, err := fs.file.WriteAt(table, fs.fatOffset(n)); err != nil {
+			return fmt.Errorf("could not write FAT #%d: %v", n, err)
+		}
+		return nil
+	}
+	for n := 0; n < int(fs.bpb.numFATs); n++ {
+		if _, err := fs.file.WriteAt(table, fs.fatOffset(n)); err != nil {
+			return fmt.Errorf("could not write FAT #%d: %v", n, err)
+		}
+	}
+	return nil
+}
+
+// SetActiveFAT disables FAT mirroring and designates FAT copy n as the single active one,
+// updating mirrorFlags and rewriting the boot sector (and its backup). n must be a valid FAT
+// index for this volume.
+func (fs *FileSystem) SetActiveFAT(n int) error {
+	if fs.ebpb32 == nil {
+		return fmt.Errorf("SetActiveFAT is only supported on FAT32 volumes")
+	}
+	if n < 0 || n >= int(fs.bpb.numFATs) {
+		return fmt.Errorf("invalid FAT index %d, volume has %d FATs", n, fs.bpb.numFATs)
+	}
+	fs.ebpb32.mirrorFlags = mirrorDisabledBit | (uint16(n) & mirrorActiveFATMask)
+	return fs.rewriteBootSector()
+}
+
+// DisableMirroring toggles whether FAT mirroring is disabled. Disabling it preserves whatever
+// active FAT index was last set (or 0 if none was); re-enabling it clears bits 3-0, since they
+// are meaningless once every copy is kept in sync again.
+func (fs *FileSystem) DisableMirroring(disable bool) error {
+	if fs.ebpb32 == nil {
+		return fmt.Errorf("DisableMirroring is only supported on FAT32 volumes")
+	}
+	if disable {
+		fs.ebpb32.mirrorFlags |= mirrorDisabledBit
+	} else {
+		fs.ebpb32.mirrorFlags = 0
+	}
+	return fs.rewriteBootSector()
+}
+
+// rewriteBootSector re-serializes the current BPB/EBPB into the existing on-disk boot sector and
+// writes it back, along with its backup mirror, via writeBootSectorTriplet. It starts from the
+// sector already on disk rather than building one from scratch, so that jmpBoot, the OEM name
+// and any installed boot code survive a mirroring change untouched.
+func (fs *FileSystem) rewriteBootSector() error {
+	sector, err := fs.readBootSector(false)
+	if err != nil {
+		return fmt.Errorf("could not read existing boot sector: %v", err)
+	}
+	ebpbBytes, err := fs.ebpb32.toBytes()
+	if err != nil {
+		return fmt.Errorf("could not serialize EBPB: %v", err)
+	}
+	copy(sector[bpbOffset:], ebpbBytes)
+	binary.LittleEndian.PutUint16(sector[bootSectorSignatureOffset:], bootSectorSignature)
+	return fs.writeBootSectorTriplet(sector)
+}
@@ -0,0 +1,197 @@
+package fat32
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestMirroringDisabled(t *testing.T) {
+	tests := []struct {
+		name        string
+		mirrorFlags uint16
+		disabled    bool
+		active      int
+	}{
+		{"mirrored, all zero", 0x0000, false, 0},
+		{"disabled, FAT 0 active", 0x0080, true, 0},
+		{"disabled, FAT 1 active", 0x0081, true, 1},
+		{"mirrored, stale low bits ignored", 0x0005, false, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bpb := &dos71EBPB{mirrorFlags: tt.mirrorFlags}
+			if got := bpb.mirroringDisabled(); got != tt.disabled {
+				t.Errorf("mirroringDisabled() = %v, want %v", got, tt.disabled)
+			}
+			if got := bpb.activeFAT(); got != tt.active {
+				t.Errorf("activeFAT() = %v, want %v", got, tt.active)
+			}
+		})
+	}
+}
+
+// fakeBlockDevice is an in-memory blockDevice used to exercise FAT read/write without a real
+// disk image.
+type fakeBlockDevice struct {
+	data []byte
+}
+
+func (f *fakeBlockDevice) ReadAt(p []byte, off int64) (int, error) {
+	return copy(p, f.data[off:]), nil
+}
+
+func (f *fakeBlockDevice) WriteAt(p []byte, off int64) (int, error) {
+	return copy(f.data[off:], p), nil
+}
+
+func newTestFileSystem(numFATs uint8, mirrorFlags uint16) *FileSystem {
+	const sectorSize = 512
+	const sectorsPerFAT = 2
+	bpb := &dos331BPB{
+		bytesPerSector:      sectorSize,
+		sectorsPerCluster:   1,
+		reservedSectorCount: 1,
+		numFATs:             numFATs,
+	}
+	ebpb := &dos71EBPB{
+		dos331BPB:             bpb,
+		sectorsPerFat:         sectorsPerFAT,
+		mirrorFlags:           mirrorFlags,
+		extendedBootSignature: shortDos71EBPB,
+	}
+	size := int(bpb.reservedSectorCount)*sectorSize + int(numFATs)*sectorsPerFAT*sectorSize
+	return &FileSystem{
+		bpb:    bpb,
+		ebpb32: ebpb,
+		fsType: fatType32,
+		file:   &fakeBlockDevice{data: make([]byte, size)},
+	}
+}
+
+func TestWriteFATMirrored(t *testing.T) {
+	fs := newTestFileSystem(2, 0x0000)
+	table := make([]byte, fs.sectorsPerFAT()*uint32(fs.bpb.bytesPerSector))
+	table[0] = 0xF8
+
+	if err := fs.writeFAT(table); err != nil {
+		t.Fatalf("writeFAT: %v", err)
+	}
+
+	for n := 0; n < 2; n++ {
+		got, err := fs.readFATCopy(n)
+		if err != nil {
+			t.Fatalf("readFATCopy(%d): %v", n, err)
+		}
+		if got[0] != 0xF8 {
+			t.Errorf("FAT #%d byte 0 = %#02x, want 0xF8", n, got[0])
+		}
+	}
+}
+
+func TestWriteFATSingleActive(t *testing.T) {
+	fs := newTestFileSystem(2, mirrorDisabledBit|1) // mirroring disabled, FAT #1 active
+	table := make([]byte, fs.sectorsPerFAT()*uint32(fs.bpb.bytesPerSector))
+	table[0] = 0xF8
+
+	if err := fs.writeFAT(table); err != nil {
+		t.Fatalf("writeFAT: %v", err)
+	}
+
+	got1, err := fs.readFATCopy(1)
+	if err != nil {
+		t.Fatalf("readFATCopy(1): %v", err)
+	}
+	if got1[0] != 0xF8 {
+		t.Errorf("active FAT #1 byte 0 = %#02x, want 0xF8", got1[0])
+	}
+
+	got0, err := fs.readFATCopy(0)
+	if err != nil {
+		t.Fatalf("readFATCopy(0): %v", err)
+	}
+	if got0[0] != 0x00 {
+		t.Errorf("inactive FAT #0 byte 0 = %#02x, want untouched 0x00", got0[0])
+	}
+}
+
+// readFATCopy reads a single specific FAT copy by index, bypassing the mirrorFlags-aware
+// selection in readFAT, so tests can inspect each copy independently.
+func (fs *FileSystem) readFATCopy(n int) ([]byte, error) {
+	size := int64(fs.sectorsPerFAT()) * int64(fs.bpb.bytesPerSector)
+	buf := make([]byte, size)
+	_, err := fs.file.ReadAt(buf, fs.fatOffset(n))
+	return buf, err
+}
+
+// seedBootSector writes a boot sector onto fs's backing device with a distinctive jmpBoot, OEM
+// name and trailing boot code, followed by a valid 0xAA55 signature, so tests can verify those
+// bytes survive a boot-sector rewrite untouched.
+func seedBootSector(fs *FileSystem) []byte {
+	sector := make([]byte, fs.bpb.bytesPerSector)
+	copy(sector[0:3], []byte{0xEB, 0x58, 0x90}) // jmpBoot: short jump
+	copy(sector[3:11], []byte("MYOEM4.2"))      // oemNameOffset
+	ebpbBytes, err := fs.ebpb32.toBytes()
+	if err != nil {
+		panic(err)
+	}
+	copy(sector[bpbOffset:], ebpbBytes)
+	copy(sector[bpbRegionEndShort:], []byte{0xDE, 0xAD, 0xBE, 0xEF}) // pre-existing boot code
+	binary.LittleEndian.PutUint16(sector[bootSectorSignatureOffset:], bootSectorSignature)
+	if _, err := fs.file.WriteAt(sector, 0); err != nil {
+		panic(err)
+	}
+	return sector
+}
+
+func TestSetActiveFATPreservesBootSector(t *testing.T) {
+	fs := newTestFileSystem(2, 0x0000)
+	seedBootSector(fs)
+
+	if err := fs.SetActiveFAT(1); err != nil {
+		t.Fatalf("SetActiveFAT: %v", err)
+	}
+
+	got := make([]byte, fs.bpb.bytesPerSector)
+	if _, err := fs.file.ReadAt(got, 0); err != nil {
+		t.Fatalf("ReadAt: %v", err)
+	}
+	if !bytes.Equal(got[0:3], []byte{0xEB, 0x58, 0x90}) {
+		t.Errorf("jmpBoot = %#v, want untouched", got[0:3])
+	}
+	if !bytes.Equal(got[3:11], []byte("MYOEM4.2")) {
+		t.Errorf("OEM name = %q, want untouched", got[3:11])
+	}
+	if !bytes.Equal(got[bpbRegionEndShort:bpbRegionEndShort+4], []byte{0xDE, 0xAD, 0xBE, 0xEF}) {
+		t.Errorf("boot code = %#v, want untouched", got[bpbRegionEndShort:bpbRegionEndShort+4])
+	}
+	if mirrorFlags := binary.LittleEndian.Uint16(got[bpbOffset+29 : bpbOffset+31]); mirrorFlags != mirrorDisabledBit|1 {
+		t.Errorf("mirrorFlags = %#04x, want %#04x", mirrorFlags, mirrorDisabledBit|1)
+	}
+}
+
+func TestDisableMirroringPreservesBootSector(t *testing.T) {
+	fs := newTestFileSystem(2, mirrorDisabledBit|1)
+	seedBootSector(fs)
+
+	if err := fs.DisableMirroring(false); err != nil {
+		t.Fatalf("DisableMirroring: %v", err)
+	}
+	if fs.ebpb32.mirrorFlags != 0 {
+		t.Errorf("mirrorFlags = %#04x, want 0", fs.ebpb32.mirrorFlags)
+	}
+
+	got := make([]byte, fs.bpb.bytesPerSector)
+	if _, err := fs.file.ReadAt(got, 0); err != nil {
+		t.Fatalf("ReadAt: %v", err)
+	}
+	if !bytes.Equal(got[0:3], []byte{0xEB, 0x58, 0x90}) {
+		t.Errorf("jmpBoot = %#v, want untouched", got[0:3])
+	}
+	if !bytes.Equal(got[3:11], []byte("MYOEM4.2")) {
+		t.Errorf("OEM name = %q, want untouched", got[3:11])
+	}
+	if !bytes.Equal(got[bpbRegionEndShort:bpbRegionEndShort+4], []byte{0xDE, 0xAD, 0xBE, 0xEF}) {
+		t.Errorf("boot code = %#v, want untouched", got[bpbRegionEndShort:bpbRegionEndShort+4])
+	}
+}
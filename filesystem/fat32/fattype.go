@@ -0,0 +1,87 @@
+package fat32
+
+// fatType identifies which of the three on-disk FAT layouts a volume uses. The type is not
+// stored anywhere on disk; it is derived purely from the geometry recorded in the BPB, per the
+// rule in the Microsoft FAT specification (and as implemented by fsck_msdosfs and Fuchsia's
+// thinfs): count the clusters in the data region and bucket on the result.
+type fatType int
+
+const (
+	// FatTypeUnknown is returned when the cluster count could not be determined
+	fatTypeUnknown fatType = iota
+	// FatType12 is a FAT12 volume, fewer than 4085 clusters
+	fatType12
+	// FatType16 is a FAT16 volume, fewer than 65525 clusters
+	fatType16
+	// FatType32 is a FAT32 volume, 65525 clusters or more
+	fatType32
+)
+
+func (t fatType) String() string {
+	switch t {
+	case fatType12:
+		return "FAT12"
+	case fatType16:
+		return "FAT16"
+	case fatType32:
+		return "FAT32"
+	default:
+		return "unknown"
+	}
+}
+
+// determineFatType applies the standard FAT-type rule to a DOS 3.31 BPB: compute the number of
+// sectors consumed by the (possibly zero-sized, for FAT32) root directory region, subtract the
+// reserved, FAT and root-directory sectors from the total to get the data region size, and
+// bucket the resulting cluster count.
+//
+// fatSz32 is the 32-bit sectors-per-FAT count (BPB_FATSz32) read from the fixed offset that
+// follows the DOS 3.31 BPB. It only applies when bpb.sectorsPerFat16 is zero, which is itself
+// the first signal that this is a FAT32 volume; callers sniffing an unknown boot sector should
+// always read it before committing to an EBPB variant.
+func determineFatType(bpb *dos331BPB, fatSz32 uint32) fatType {
+	if bpb == nil {
+		return fatTypeUnknown
+	}
+	rootDirSectors := rootDirectorySectors(bpb)
+
+	fatSz := uint32(bpb.sectorsPerFat16)
+	if fatSz == 0 {
+		fatSz = fatSz32
+	}
+
+	totSec := uint32(bpb.totalSectors16)
+	if totSec == 0 {
+		totSec = bpb.totalSectors32
+	}
+
+	reservedSec := uint32(bpb.reservedSectorCount)
+	numFATs := uint32(bpb.numFATs)
+
+	dataSec := totSec - (reservedSec + numFATs*fatSz + uint32(rootDirSectors))
+	if bpb.sectorsPerCluster == 0 {
+		return fatTypeUnknown
+	}
+	countOfClusters := dataSec / uint32(bpb.sectorsPerCluster)
+
+	switch {
+	case countOfClusters < 4085:
+		return fatType12
+	case countOfClusters < 65525:
+		return fatType16
+	default:
+		return fatType32
+	}
+}
+
+// rootDirectorySectors returns the number of sectors consumed by a fixed-size FAT12/16 root
+// directory: ceil((RootEntCnt*32)/BytesPerSec). FAT32 volumes record a zero root entry count
+// here, since the root directory lives in the regular cluster chain instead, so this returns 0
+// for them.
+func rootDirectorySectors(bpb *dos331BPB) uint16 {
+	if bpb.bytesPerSector == 0 {
+		return 0
+	}
+	numerator := uint32(bpb.rootEntryCount) * 32
+	return uint16((numerator + uint32(bpb.bytesPerSector) - 1) / uint32(bpb.bytesPerSector))
+}
@@ -0,0 +1,36 @@
+package fat32
+
+import "io"
+
+// blockDevice is the minimal read/write surface FileSystem needs from its backing disk image.
+type blockDevice interface {
+	io.ReaderAt
+	io.WriterAt
+}
+
+// FileSystem represents a FAT filesystem found on a disk image. Despite the package name, it
+// now covers all three on-disk FAT layouts - FAT12, FAT16 and FAT32 - which are distinguished
+// purely by the cluster count derived from the BPB geometry; callers that only care about the
+// FAT32-specific layout (FSInfo, 32-bit FAT entries, cluster-chained root directory) can switch
+// on FSType().
+type FileSystem struct {
+	bpb      *dos331BPB // bpb is the common DOS 3.31 BPB shared by every FAT type
+	ebpb32   *dos71EBPB // ebpb32 is set when fsType is fatType32
+	ebpb     *dos40EBPB // ebpb is set when fsType is fatType12 or fatType16
+	fsType   fatType
+	rootDir  *fixedRootDirectory // rootDir is set when fsType is fatType12 or fatType16
+	fsis     *fsInfoSector       // fsis is set when fsType is fatType32; nil for FAT12/16, which have no FSInfo sector
+	file     blockDevice         // file is the backing disk image
+	bootCode BootCode            // bootCode is the installed bootstrap program, nil until SetBootCode is called
+}
+
+// FSType returns a human-readable name for the detected FAT type: "FAT12", "FAT16" or "FAT32".
+func (fs *FileSystem) FSType() string {
+	return fs.fsType.String()
+}
+
+// Type satisfies the generic filesystem.FileSystem interface implemented elsewhere in this
+// package.
+func (fs *FileSystem) Type() string {
+	return fs.fsType.String()
+}
@@ -0,0 +1,339 @@
+package fat32
+
+import "fmt"
+
+// badClusterMarker12/16/32 flag a cluster as physically damaged; such a cluster is never
+// allocated to a file and is skipped entirely by the free-cluster scan.
+const (
+	badClusterMarker12 uint16 = 0x0FF7
+	badClusterMarker16 uint16 = 0xFFF7
+	badClusterMarker32 uint32 = 0x0FFFFFF7
+)
+
+// CrossLinkedCluster reports a single cluster that is reachable from more than one file's
+// chain - a corruption fsck_msdosfs calls "cross-linked".
+type CrossLinkedCluster struct {
+	Cluster uint32
+	Owners  []string // Owners are the paths of every file whose chain reaches this cluster
+}
+
+// SizeMismatch reports a file whose recorded size does not match the length of its cluster
+// chain, rounded up to a whole cluster.
+type SizeMismatch struct {
+	Path       string
+	FileSize   uint32
+	ChainBytes uint32
+}
+
+// CheckReport is the structured result of FileSystem.Check.
+type CheckReport struct {
+	BootSectorMismatches []Mismatch
+	MediaDescriptorOK    bool
+	EndOfChainMarkerOK   bool
+	CrossLinked          []CrossLinkedCluster
+	LostChains           [][]uint32 // LostChains are clusters allocated in the FAT but unreachable from any directory
+	BadClusters          []uint32
+	OutOfRangeClusters   []uint32
+	SizeMismatches       []SizeMismatch
+	ObservedFreeClusters uint32
+	ReportedFreeClusters uint32
+	FreeCountMismatch    bool
+}
+
+// owner tracks, for a visited cluster, which file claimed it first and the cluster that
+// precedes it in that file's chain, so a later truncation during Repair knows where to cut.
+type owner struct {
+	path int // index into fsckWalker.paths
+	prev uint32
+}
+
+type fsckWalker struct {
+	fs      *FileSystem
+	fat     []byte
+	count   uint32
+	visited map[uint32]owner
+	crossed map[uint32][]string
+	paths   []string
+	report  *CheckReport
+}
+
+// Check performs a read-only scan of the volume modeled on fsck_msdosfs: it verifies the boot
+// record and FAT housekeeping bytes, walks every directory starting at the root, and
+// cross-references what it finds against the FAT to detect cross-linked chains, lost chains,
+// bad clusters, out-of-range references and file-size mismatches, finally reconciling the
+// observed free cluster count against FSInfo.
+func (fs *FileSystem) Check() (*CheckReport, error) {
+	mismatches, err := fs.Verify()
+	if err != nil {
+		return nil, fmt.Errorf("could not verify boot sector: %v", err)
+	}
+
+	w, err := fs.scan()
+	if err != nil {
+		return nil, err
+	}
+	report := w.report
+	report.BootSectorMismatches = mismatches
+	report.MediaDescriptorOK = fatEntryGet(fs.fsType, w.fat, 0)&0xFF == uint32(fs.bpb.media)
+	report.EndOfChainMarkerOK = isEndOfChain(fs.fsType, fatEntryGet(fs.fsType, w.fat, 1))
+
+	for cluster, owners := range w.crossed {
+		report.CrossLinked = append(report.CrossLinked, CrossLinkedCluster{Cluster: cluster, Owners: owners})
+	}
+
+	if free, ok := fs.FreeClusters(); ok {
+		report.ReportedFreeClusters = free
+		report.FreeCountMismatch = free != report.ObservedFreeClusters
+	} else {
+		report.FreeCountMismatch = true
+	}
+
+	return report, nil
+}
+
+// scan reads the FAT and walks the full directory tree from the root, building the fsckWalker's
+// ownership and cross-link maps, then classifies every cluster in the FAT as free, bad, or the
+// start of a lost chain. It is the shared core of Check and Repair: Repair needs LostChains (to
+// recover) and the ownership map (for each cross-linked owner's predecessor cluster) exactly as
+// Check reports them, not a report built by only part of the same scan.
+func (fs *FileSystem) scan() (*fsckWalker, error) {
+	fat, err := fs.readFAT()
+	if err != nil {
+		return nil, fmt.Errorf("could not read FAT: %v", err)
+	}
+
+	w := &fsckWalker{
+		fs:      fs,
+		fat:     fat,
+		count:   fs.countOfClusters(),
+		visited: map[uint32]owner{},
+		crossed: map[uint32][]string{},
+		report:  &CheckReport{},
+	}
+
+	root, err := fs.rootClusters()
+	if err != nil {
+		return nil, err
+	}
+	if err := w.walkChain(root, "/", 0); err != nil {
+		return nil, err
+	}
+	if err := w.walkDirectory(root, "/"); err != nil {
+		return nil, err
+	}
+	w.classifyClusters()
+	return w, nil
+}
+
+// classifyClusters walks every cluster in the FAT once directory traversal has populated
+// w.visited, recording bad clusters, the start of every lost chain (allocated but unreachable
+// from any directory), and the observed free cluster count - scan-level facts that both Check
+// reports and Repair acts on directly.
+func (w *fsckWalker) classifyClusters() {
+	var observedFree uint32
+	for cluster := uint32(2); cluster < w.count+2; cluster++ {
+		entry := fatEntryGet(w.fs.fsType, w.fat, cluster)
+		if entry == 0 {
+			observedFree++
+			continue
+		}
+		if isBadCluster(w.fs.fsType, entry) {
+			w.report.BadClusters = append(w.report.BadClusters, cluster)
+			continue
+		}
+		if _, ok := w.visited[cluster]; ok {
+			continue
+		}
+		// allocated but unreachable from any directory: the start of a lost chain, unless it
+		// is itself the continuation of one already recorded.
+		chain := w.collectLostChain(cluster)
+		for _, c := range chain {
+			w.visited[c] = owner{}
+		}
+		w.report.LostChains = append(w.report.LostChains, chain)
+	}
+	w.report.ObservedFreeClusters = observedFree
+}
+
+// rootClusters returns the cluster chain (FAT32) or the sentinel for a fixed-size root
+// directory (FAT12/16, which has no chain to walk - walkDirectory handles it directly).
+func (fs *FileSystem) rootClusters() ([]uint32, error) {
+	if fs.fsType != fatType32 {
+		return nil, nil
+	}
+	if fs.ebpb32 == nil || fs.ebpb32.rootDirectoryCluster < 2 {
+		return nil, fmt.Errorf("invalid root directory cluster")
+	}
+	return []uint32{fs.ebpb32.rootDirectoryCluster}, nil
+}
+
+// walkChain records ownership of every cluster in a chain starting at start, detecting
+// cross-links against clusters already owned by a different path.
+func (w *fsckWalker) walkChain(start []uint32, path string, _ int) error {
+	if len(start) == 0 {
+		return nil // FAT12/16 fixed root directory: no chain to record
+	}
+	pathIdx := len(w.paths)
+	w.paths = append(w.paths, path)
+
+	prev := uint32(0)
+	cluster := start[0]
+	seen := map[uint32]bool{}
+	for cluster >= 2 && !isEndOfChain(w.fs.fsType, cluster) {
+		if seen[cluster] {
+			break // chain loops back on itself; stop rather than spin forever
+		}
+		seen[cluster] = true
+
+		if cluster >= w.count+2 {
+			w.report.OutOfRangeClusters = append(w.report.OutOfRangeClusters, cluster)
+			break
+		}
+		if existing, ok := w.visited[cluster]; ok {
+			if _, recorded := w.crossed[cluster]; !recorded {
+				w.crossed[cluster] = append(w.crossed[cluster], w.paths[existing.path])
+			}
+			w.crossed[cluster] = append(w.crossed[cluster], path)
+		} else {
+			w.visited[cluster] = owner{path: pathIdx, prev: prev}
+		}
+		prev = cluster
+		cluster = fatEntryGet(w.fs.fsType, w.fat, cluster)
+	}
+	return nil
+}
+
+// walkDirectory reads every entry in the directory rooted at clusters (or the fixed root
+// region, when clusters is empty), recursing into subdirectories and recording each file's
+// chain via walkChain.
+func (w *fsckWalker) walkDirectory(clusters []uint32, path string) error {
+	entries, err := w.readDirectoryEntries(clusters)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if e.isEnd() {
+			break
+		}
+		if e.isDeleted() || e.isLongName() || e.isVolumeLabel() {
+			continue
+		}
+		name := e.shortName()
+		if name == "." || name == ".." {
+			continue
+		}
+		childPath := path + name
+		start := e.firstCluster()
+		if start < 2 {
+			continue // empty file or directory, nothing allocated
+		}
+		if err := w.walkChain([]uint32{start}, childPath, 0); err != nil {
+			return err
+		}
+		if e.isDirectory() {
+			if err := w.walkDirectory([]uint32{start}, childPath+"/"); err != nil {
+				return err
+			}
+			continue
+		}
+		chainLen := w.chainLength(start)
+		expected := (e.fileSize + w.fs.bytesPerCluster() - 1) / w.fs.bytesPerCluster()
+		if e.fileSize > 0 && expected != chainLen {
+			w.report.SizeMismatches = append(w.report.SizeMismatches, SizeMismatch{
+				Path:       childPath,
+				FileSize:   e.fileSize,
+				ChainBytes: chainLen * w.fs.bytesPerCluster(),
+			})
+		}
+	}
+	return nil
+}
+
+// readDirectoryEntries reads all 32-byte entries for either a cluster-chained directory
+// (FAT32, or any non-root directory) or the fixed-size root directory region (FAT12/16).
+func (w *fsckWalker) readDirectoryEntries(clusters []uint32) ([]dirEntry, error) {
+	var raw []byte
+	if len(clusters) == 0 {
+		size := int64(w.fs.rootDir.sectorCount) * int64(w.fs.bpb.bytesPerSector)
+		raw = make([]byte, size)
+		if _, err := w.fs.file.ReadAt(raw, int64(w.fs.rootDir.startSector)*int64(w.fs.bpb.bytesPerSector)); err != nil {
+			return nil, fmt.Errorf("could not read root directory: %v", err)
+		}
+	} else {
+		cluster := clusters[0]
+		for cluster >= 2 && !isEndOfChain(w.fs.fsType, cluster) {
+			data, err := w.fs.readCluster(cluster)
+			if err != nil {
+				return nil, fmt.Errorf("could not read directory cluster %d: %v", cluster, err)
+			}
+			raw = append(raw, data...)
+			cluster = fatEntryGet(w.fs.fsType, w.fat, cluster)
+		}
+	}
+
+	entries := make([]dirEntry, 0, len(raw)/32)
+	for off := 0; off+32 <= len(raw); off += 32 {
+		entries = append(entries, dirEntryFromBytes(raw[off:off+32]))
+	}
+	return entries, nil
+}
+
+// chainLength returns the number of clusters in the chain starting at start.
+func (w *fsckWalker) chainLength(start uint32) uint32 {
+	var n uint32
+	cluster := start
+	seen := map[uint32]bool{}
+	for cluster >= 2 && !isEndOfChain(w.fs.fsType, cluster) && !seen[cluster] {
+		seen[cluster] = true
+		n++
+		cluster = fatEntryGet(w.fs.fsType, w.fat, cluster)
+	}
+	return n
+}
+
+// collectLostChain follows the FAT chain starting at an unreachable cluster, returning every
+// cluster in it so Repair can convert the whole chain into a single recovery file. It stops
+// before a cluster already recorded in w.visited rather than walking into it: that cluster is
+// the start of (or a link in) a chain the scan already reported, so re-adding it here would
+// double-count it across two different LostChains entries - and, in Repair, create a new
+// cross-link between two supposedly-separate recovery files out of the very corruption it was
+// meant to fix.
+func (w *fsckWalker) collectLostChain(start uint32) []uint32 {
+	var chain []uint32
+	cluster := start
+	seen := map[uint32]bool{}
+	for cluster >= 2 && cluster < w.count+2 && !isEndOfChain(w.fs.fsType, cluster) && !seen[cluster] {
+		if _, ok := w.visited[cluster]; ok {
+			break
+		}
+		seen[cluster] = true
+		chain = append(chain, cluster)
+		cluster = fatEntryGet(w.fs.fsType, w.fat, cluster)
+	}
+	return chain
+}
+
+// isEndOfChain reports whether entry marks the end of a cluster chain for the given FAT type.
+func isEndOfChain(t fatType, entry uint32) bool {
+	switch t {
+	case fatType12:
+		return entry >= 0x0FF8
+	case fatType16:
+		return entry >= 0xFFF8
+	default:
+		return entry >= 0x0FFFFFF8
+	}
+}
+
+// isBadCluster reports whether entry is the bad-cluster marker for the given FAT type.
+func isBadCluster(t fatType, entry uint32) bool {
+	switch t {
+	case fatType12:
+		return entry == uint32(badClusterMarker12)
+	case fatType16:
+		return entry == uint32(badClusterMarker16)
+	default:
+		return entry == badClusterMarker32
+	}
+}
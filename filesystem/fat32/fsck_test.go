@@ -0,0 +1,69 @@
+package fat32
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestWalkChainCrossLinkOwnerDedup exercises a cluster crossed by three separate chains,
+// verifying the first owner is recorded only once rather than once per additional crosser.
+func TestWalkChainCrossLinkOwnerDedup(t *testing.T) {
+	const shared = 2
+	fat := make([]byte, 4*6)
+	fatEntrySet(fatType32, fat, shared, endOfChainMarker(fatType32))
+
+	w := &fsckWalker{
+		fs:      &FileSystem{fsType: fatType32},
+		fat:     fat,
+		count:   4,
+		visited: map[uint32]owner{},
+		crossed: map[uint32][]string{},
+	}
+
+	if err := w.walkChain([]uint32{shared}, "/A", 0); err != nil {
+		t.Fatalf("walkChain A: %v", err)
+	}
+	if err := w.walkChain([]uint32{shared}, "/B", 0); err != nil {
+		t.Fatalf("walkChain B: %v", err)
+	}
+	if err := w.walkChain([]uint32{shared}, "/C", 0); err != nil {
+		t.Fatalf("walkChain C: %v", err)
+	}
+
+	want := []string{"/A", "/B", "/C"}
+	if got := w.crossed[shared]; !reflect.DeepEqual(got, want) {
+		t.Errorf("crossed[%d] = %v, want %v", shared, got, want)
+	}
+}
+
+// TestCollectLostChainDoesNotDoubleCount exercises two orphan chains discovered in ascending
+// cluster order where the later one (starting at 4) points backward into the earlier one
+// (cluster 3, FAT[3]=EOC): collectLostChain for cluster 4 must stop at cluster 3 once it's
+// already been recorded by the cluster-3 scan, rather than re-reporting it as part of a second
+// chain.
+func TestCollectLostChainDoesNotDoubleCount(t *testing.T) {
+	fat := make([]byte, 4*6)
+	fatEntrySet(fatType32, fat, 3, endOfChainMarker(fatType32))
+	fatEntrySet(fatType32, fat, 4, 3)
+
+	w := &fsckWalker{
+		fs:      &FileSystem{fsType: fatType32},
+		fat:     fat,
+		count:   4,
+		visited: map[uint32]owner{},
+		crossed: map[uint32][]string{},
+	}
+
+	chain3 := w.collectLostChain(3)
+	if want := []uint32{3}; !reflect.DeepEqual(chain3, want) {
+		t.Fatalf("collectLostChain(3) = %v, want %v", chain3, want)
+	}
+	for _, c := range chain3 {
+		w.visited[c] = owner{}
+	}
+
+	chain4 := w.collectLostChain(4)
+	if want := []uint32{4}; !reflect.DeepEqual(chain4, want) {
+		t.Errorf("collectLostChain(4) = %v, want %v (cluster 3 already belongs to another lost chain)", chain4, want)
+	}
+}
@@ -0,0 +1,104 @@
+package fat32
+
+import "fmt"
+
+// RepairReport summarizes the corrective actions FileSystem.Repair took.
+type RepairReport struct {
+	TruncatedFiles   []string // TruncatedFiles lists the non-primary owners of each cross-linked chain that were cut
+	RecoveredFiles   []string // RecoveredFiles lists the FOUND.000/FILE####.CHK entries created for lost chains
+	FSInfoReconciled bool
+}
+
+// Repair fixes the corruption found by a prior Check: cross-linked files are truncated at the
+// fork (every owner after the first keeps its clusters up to, but not including, the shared
+// one), lost chains are relinked into FOUND.000 as FILE####.CHK recovery entries, and a stale
+// FSInfo free-cluster count is recomputed and rewritten. Repair re-scans the volume itself
+// rather than trusting the caller's report, since acting on stale cluster ownership data would
+// risk cutting the wrong file.
+func (fs *FileSystem) Repair() (*RepairReport, error) {
+	w, err := fs.scan()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RepairReport{}
+
+	for cluster, owners := range w.crossed {
+		// the first owner recorded keeps the cluster; every subsequent owner is truncated at
+		// its own predecessor of that cluster.
+		for _, path := range owners[1:] {
+			if err := fs.truncateBefore(w, path, cluster); err != nil {
+				return nil, fmt.Errorf("could not truncate cross-linked file %s: %v", path, err)
+			}
+			result.TruncatedFiles = append(result.TruncatedFiles, path)
+		}
+	}
+
+	for i, chain := range w.report.LostChains {
+		name := fmt.Sprintf("FOUND.000/FILE%04d.CHK", i+1)
+		if err := fs.recoverChain(w.fat, chain, name); err != nil {
+			return nil, fmt.Errorf("could not recover lost chain as %s: %v", name, err)
+		}
+		result.RecoveredFiles = append(result.RecoveredFiles, name)
+	}
+
+	if err := fs.writeFAT(w.fat); err != nil {
+		return nil, fmt.Errorf("could not write repaired FAT: %v", err)
+	}
+
+	observedFree := fs.recomputeFreeClusters(w.fat)
+	fs.updateFSInfo(observedFree, fs.nextFreeHint())
+	if err := fs.syncFSInfo(); err != nil {
+		return nil, fmt.Errorf("could not reconcile FSInfo: %v", err)
+	}
+	result.FSInfoReconciled = true
+
+	return result, nil
+}
+
+// truncateBefore finds path's predecessor of cluster within w's ownership map and marks it
+// end-of-chain, cutting path off before the cross-linked cluster.
+func (fs *FileSystem) truncateBefore(w *fsckWalker, path string, cluster uint32) error {
+	own, ok := w.visited[cluster]
+	if !ok || w.paths[own.path] != path {
+		// the recorded owner is a different path than the one being truncated; walk the FAT
+		// looking for the entry that points at cluster instead.
+		for candidate := uint32(2); candidate < w.count+2; candidate++ {
+			if fatEntryGet(fs.fsType, w.fat, candidate) == cluster {
+				own.prev = candidate
+				break
+			}
+		}
+	}
+	if own.prev == 0 {
+		return fmt.Errorf("could not locate predecessor of cluster %d for %s", cluster, path)
+	}
+	fatEntrySet(fs.fsType, w.fat, own.prev, endOfChainMarker(fs.fsType))
+	return nil
+}
+
+// recoverChain writes a new directory entry named name (created in FOUND.000, which is
+// created if it does not already exist) that claims an already-allocated lost chain. Any
+// cluster this needs to allocate (for FOUND.000 itself, or to extend it) is taken from and
+// recorded directly in fat - Repair's single in-progress FAT snapshot - rather than a fresh
+// read of the on-disk FAT, so that fs.writeFAT(fat) at the end of Repair is the one write that
+// captures every change a recovery made.
+func (fs *FileSystem) recoverChain(fat []byte, chain []uint32, name string) error {
+	if len(chain) == 0 {
+		return nil
+	}
+	size := uint32(len(chain)) * fs.bytesPerCluster()
+	return fs.createRecoveryEntry(fat, name, chain[0], size)
+}
+
+// recomputeFreeClusters scans a FAT table directly, counting entries equal to zero.
+func (fs *FileSystem) recomputeFreeClusters(fat []byte) uint32 {
+	var free uint32
+	count := fs.countOfClusters()
+	for cluster := uint32(2); cluster < count+2; cluster++ {
+		if fatEntryGet(fs.fsType, fat, cluster) == 0 {
+			free++
+		}
+	}
+	return free
+}
@@ -0,0 +1,136 @@
+package fat32
+
+import "testing"
+
+// newTestFAT32VolumeWithLostChain builds a minimal in-memory FAT32 volume with an empty root
+// directory and a single allocated-but-unreferenced cluster (5), so Check reports it as a lost
+// chain and Repair has something real to recover. Clusters 3, 4, 6, 7, 8 and 9 are left free for
+// Repair's own allocations (FOUND.000 and its directory entries).
+func newTestFAT32VolumeWithLostChain(t *testing.T) *FileSystem {
+	t.Helper()
+
+	const sectorSize = 512
+	const reservedSectors = 2 // sector 0: unused in this fixture; sector 1: FSInfo
+	const sectorsPerFat = 1
+	const dataSectors = 8 // clusters 2-9
+	const rootCluster = 2
+	const lostCluster = 5
+
+	bpb := &dos331BPB{
+		bytesPerSector:      sectorSize,
+		sectorsPerCluster:   1,
+		reservedSectorCount: reservedSectors,
+		numFATs:             1,
+		totalSectors32:      reservedSectors + sectorsPerFat + dataSectors,
+	}
+	ebpb := &dos71EBPB{
+		dos331BPB:             bpb,
+		sectorsPerFat:         sectorsPerFat,
+		rootDirectoryCluster:  rootCluster,
+		fsInformationSector:   1,
+		extendedBootSignature: shortDos71EBPB,
+	}
+
+	fs := &FileSystem{
+		bpb:    bpb,
+		ebpb32: ebpb,
+		fsType: fatType32,
+		file:   &fakeBlockDevice{data: make([]byte, int(bpb.totalSectors32)*sectorSize)},
+		fsis:   &fsInfoSector{freeCount: fsInfoUnknown, nextFree: 3},
+	}
+
+	fat := make([]byte, sectorsPerFat*sectorSize)
+	fatEntrySet(fatType32, fat, rootCluster, endOfChainMarker(fatType32))
+	fatEntrySet(fatType32, fat, lostCluster, endOfChainMarker(fatType32))
+	if err := fs.writeFAT(fat); err != nil {
+		t.Fatalf("writeFAT: %v", err)
+	}
+
+	// root directory cluster is left all-zero: an empty directory, so cluster 5 is
+	// unreachable from it and Check/scan will classify it as a lost chain.
+	return fs
+}
+
+// TestRepairRecoversLostChain exercises Repair end-to-end against a volume with a genuine lost
+// chain, rather than only unit-testing collectLostChain in isolation: Repair must actually
+// create the FOUND.000/FILE####.CHK recovery entry, not silently no-op because it never sees the
+// lost chain Check would have reported for the same volume.
+func TestRepairRecoversLostChain(t *testing.T) {
+	fs := newTestFAT32VolumeWithLostChain(t)
+
+	report, err := fs.Repair()
+	if err != nil {
+		t.Fatalf("Repair: %v", err)
+	}
+	if want := []string{"FOUND.000/FILE0001.CHK"}; len(report.RecoveredFiles) != 1 || report.RecoveredFiles[0] != want[0] {
+		t.Fatalf("RecoveredFiles = %v, want %v", report.RecoveredFiles, want)
+	}
+	if len(report.TruncatedFiles) != 0 {
+		t.Errorf("TruncatedFiles = %v, want none", report.TruncatedFiles)
+	}
+	if !report.FSInfoReconciled {
+		t.Error("FSInfoReconciled = false, want true")
+	}
+
+	root, err := fs.rootClusters()
+	if err != nil {
+		t.Fatalf("rootClusters: %v", err)
+	}
+	rootEntries, err := fs.readRootEntries(root)
+	if err != nil {
+		t.Fatalf("readRootEntries: %v", err)
+	}
+	var foundDir *dirEntry
+	for i := range rootEntries {
+		e := rootEntries[i]
+		if e.isEnd() {
+			break
+		}
+		if e.isDirectory() && e.shortName() == "FOUND.000" {
+			foundDir = &e
+			break
+		}
+	}
+	if foundDir == nil {
+		t.Fatal("root directory has no FOUND.000 entry")
+	}
+
+	// The bug this guards against: Repair's final writeFAT(w.fat) used to overwrite every FAT
+	// entry allocateCluster set while building FOUND.000, since those allocations were made
+	// against - and written back via - their own independent FAT snapshot rather than the one
+	// Repair writes at the end. If that regressed, the cluster FOUND.000 was just linked into
+	// would read back as free.
+	fat, err := fs.readFAT()
+	if err != nil {
+		t.Fatalf("readFAT: %v", err)
+	}
+	if got := fatEntryGet(fatType32, fat, foundDir.firstCluster()); got != endOfChainMarker(fatType32) {
+		t.Errorf("FAT entry for FOUND.000's cluster %d = %#x, want end-of-chain marker %#x (cluster reverted to free)",
+			foundDir.firstCluster(), got, endOfChainMarker(fatType32))
+	}
+
+	foundEntries, err := fs.readRootEntries([]uint32{foundDir.firstCluster()})
+	if err != nil {
+		t.Fatalf("readRootEntries(FOUND.000): %v", err)
+	}
+	var recovered *dirEntry
+	for i := range foundEntries {
+		e := foundEntries[i]
+		if e.isEnd() {
+			break
+		}
+		if e.shortName() == "FILE0001.CHK" {
+			recovered = &e
+			break
+		}
+	}
+	if recovered == nil {
+		t.Fatal("FOUND.000 has no FILE0001.CHK entry")
+	}
+	if recovered.firstCluster() != 5 {
+		t.Errorf("FILE0001.CHK first cluster = %d, want 5", recovered.firstCluster())
+	}
+	if got := fatEntryGet(fatType32, fat, 5); got != endOfChainMarker(fatType32) {
+		t.Errorf("FAT entry for recovered cluster 5 = %#x, want end-of-chain marker %#x", got, endOfChainMarker(fatType32))
+	}
+}
@@ -0,0 +1,133 @@
+package fat32
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	// fsiLeadSig is the signature at the start of the FSInfo sector
+	fsiLeadSig uint32 = 0x41615252
+	// fsiStrucSig is the signature in the middle of the FSInfo sector
+	fsiStrucSig uint32 = 0x61417272
+	// fsiTrailSig is the signature at the end of the FSInfo sector
+	fsiTrailSig uint32 = 0xAA550000
+	// fsInfoUnknown is the sentinel value meaning "count/hint not known, must be computed"
+	fsInfoUnknown uint32 = 0xFFFFFFFF
+)
+
+// fsInfoSector is the FAT32 filesystem information sector, pointed to by
+// dos71EBPB.fsInformationSector. It caches the last known count of free clusters and a hint
+// for where to resume the next free-cluster search, so that a driver does not have to scan the
+// entire FAT just to report free space or find a cluster to allocate.
+type fsInfoSector struct {
+	freeCount uint32 // FreeCount is the last known free cluster count, or fsInfoUnknown if it must be recomputed
+	nextFree  uint32 // NextFree is a hint for the first free cluster to search from, or fsInfoUnknown if there is no hint
+}
+
+// fsInfoSectorFromBytes reads the FSInfo sector from a 512-byte slice, validating all three
+// signatures.
+func fsInfoSectorFromBytes(b []byte) (*fsInfoSector, error) {
+	if len(b) != 512 {
+		return nil, errors.New("cannot read FSInfo sector from invalid byte slice, must be precisely 512 bytes")
+	}
+	leadSig := binary.LittleEndian.Uint32(b[0:4])
+	if leadSig != fsiLeadSig {
+		return nil, fmt.Errorf("invalid FSInfo lead signature: %#08x", leadSig)
+	}
+	strucSig := binary.LittleEndian.Uint32(b[484:488])
+	if strucSig != fsiStrucSig {
+		return nil, fmt.Errorf("invalid FSInfo structure signature: %#08x", strucSig)
+	}
+	trailSig := binary.LittleEndian.Uint32(b[508:512])
+	if trailSig != fsiTrailSig {
+		return nil, fmt.Errorf("invalid FSInfo trail signature: %#08x", trailSig)
+	}
+	fsis := fsInfoSector{
+		freeCount: binary.LittleEndian.Uint32(b[488:492]),
+		nextFree:  binary.LittleEndian.Uint32(b[492:496]),
+	}
+	return &fsis, nil
+}
+
+// toBytes returns the FSInfo sector as a 512-byte slice ready to write to disk.
+func (fsis *fsInfoSector) toBytes() []byte {
+	b := make([]byte, 512, 512)
+	binary.LittleEndian.PutUint32(b[0:4], fsiLeadSig)
+	binary.LittleEndian.PutUint32(b[484:488], fsiStrucSig)
+	binary.LittleEndian.PutUint32(b[488:492], fsis.freeCount)
+	binary.LittleEndian.PutUint32(b[492:496], fsis.nextFree)
+	binary.LittleEndian.PutUint32(b[508:512], fsiTrailSig)
+	return b
+}
+
+// FreeClusters returns the cached count of free clusters, or false if the count is not known
+// and must be computed by scanning the FAT.
+func (fs *FileSystem) FreeClusters() (uint32, bool) {
+	if fs.fsis == nil || fs.fsis.freeCount == fsInfoUnknown {
+		return 0, false
+	}
+	return fs.fsis.freeCount, true
+}
+
+// nextFreeHint returns the cluster to begin the next allocation search from, falling back to
+// the first valid data cluster when there is no hint.
+func (fs *FileSystem) nextFreeHint() uint32 {
+	if fs.fsis == nil || fs.fsis.nextFree == fsInfoUnknown || fs.fsis.nextFree < 2 {
+		return 2
+	}
+	return fs.fsis.nextFree
+}
+
+// updateFSInfo records the free cluster count and next-free hint after an allocation or
+// deallocation, ready to be flushed to disk on sync.
+func (fs *FileSystem) updateFSInfo(freeCount, nextFree uint32) {
+	if fs.fsis == nil {
+		fs.fsis = &fsInfoSector{}
+	}
+	fs.fsis.freeCount = freeCount
+	fs.fsis.nextFree = nextFree
+}
+
+// loadFSInfo reads the FSInfo sector pointed to by the FAT32 EBPB and caches it on fs. It is a
+// no-op for FAT12/16, which have no FSInfo sector.
+func (fs *FileSystem) loadFSInfo() error {
+	if fs.fsType != fatType32 || fs.ebpb32 == nil || fs.ebpb32.fsInformationSector == 0 {
+		return nil
+	}
+	b := make([]byte, 512, 512)
+	offset := int64(fs.ebpb32.fsInformationSector) * int64(fs.bpb.bytesPerSector)
+	if _, err := fs.file.ReadAt(b, offset); err != nil {
+		return fmt.Errorf("could not read FSInfo sector: %v", err)
+	}
+	fsis, err := fsInfoSectorFromBytes(b)
+	if err != nil {
+		return fmt.Errorf("could not parse FSInfo sector: %v", err)
+	}
+	fs.fsis = fsis
+	return nil
+}
+
+// syncFSInfo writes the current free-cluster count and next-free hint to the primary FSInfo
+// sector and its backup copy, mirrored at backupBootSector+1 per the FAT32 spec.
+func (fs *FileSystem) syncFSInfo() error {
+	if fs.fsType != fatType32 || fs.ebpb32 == nil || fs.fsis == nil {
+		return nil
+	}
+	b := fs.fsis.toBytes()
+	sectorSize := int64(fs.bpb.bytesPerSector)
+
+	primary := int64(fs.ebpb32.fsInformationSector) * sectorSize
+	if _, err := fs.file.WriteAt(b, primary); err != nil {
+		return fmt.Errorf("could not write FSInfo sector: %v", err)
+	}
+
+	if fs.ebpb32.backupBootSector != 0 {
+		backup := (int64(fs.ebpb32.backupBootSector) + 1) * sectorSize
+		if _, err := fs.file.WriteAt(b, backup); err != nil {
+			return fmt.Errorf("could not write backup FSInfo sector: %v", err)
+		}
+	}
+	return nil
+}
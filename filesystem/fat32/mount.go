@@ -0,0 +1,175 @@
+package fat32
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// ReadOptions controls optional behavior of ReadWithOptions.
+type ReadOptions struct {
+	// Tolerant downgrades BPB/EBPB validation failures (see validate.go) from a mount error to
+	// non-fatal warnings: the volume still mounts, but ReadWithOptions returns the warnings for
+	// the caller to inspect, log, or act on (e.g. before running Check/Repair).
+	Tolerant bool
+}
+
+// Read opens an existing FAT12, FAT16 or FAT32 volume on file under strict validation: a boot
+// sector that fails any check in validate.go fails the mount. It is equivalent to
+// ReadWithOptions with the zero ReadOptions; callers that need a tolerant read, or that want the
+// validation warnings even when they're non-fatal, should call ReadWithOptions directly.
+func Read(file blockDevice, size int64) (*FileSystem, error) {
+	fs, _, err := ReadWithOptions(file, size, ReadOptions{})
+	return fs, err
+}
+
+// ReadWithOptions opens an existing FAT12, FAT16 or FAT32 volume on file, determining the
+// on-disk FAT type from the BPB geometry (per determineFatType) rather than trusting any
+// out-of-band hint, then parsing whichever EBPB form that type uses. For FAT32 it also loads the
+// FSInfo sector, so FreeClusters reports a cached count instead of requiring a full FAT scan
+// right after mount.
+//
+// If the primary boot sector is unusable, ReadWithOptions falls back to the backup boot sector
+// (FAT32 only, via readBootSector) rather than failing outright - but since the backup's
+// location is itself a field of the FAT32 EBPB, ReadWithOptions must learn that field from a
+// first, unvalidated pass over the primary sector before it can even attempt that fallback. That
+// first pass tolerates a primary EBPB that fails to parse outright (not just one that parses but
+// fails its signature check) by peeking the field directly rather than giving up on the backup
+// before readBootSector gets to try it.
+//
+// Once the authoritative boot sector is settled, its BPB/EBPB is run through Validate; per
+// opts.Tolerant the result either fails the mount or is returned as warnings alongside fs.
+//
+// size is the total size of file in bytes; it is only used for a minimal sanity check, since the
+// rest of the volume's geometry comes entirely from the BPB itself.
+func ReadWithOptions(file blockDevice, size int64, opts ReadOptions) (fs *FileSystem, warnings []error, err error) {
+	if size < 512 {
+		return nil, nil, fmt.Errorf("volume is too small to contain a FAT boot sector: %d bytes", size)
+	}
+
+	probe := make([]byte, 512)
+	if _, err := file.ReadAt(probe, 0); err != nil {
+		return nil, nil, fmt.Errorf("could not read boot sector: %v", err)
+	}
+
+	bpb, fsType, err := parseBPBAndType(probe)
+	if err != nil {
+		return nil, nil, err
+	}
+	fs = &FileSystem{bpb: bpb, fsType: fsType, file: file}
+
+	if fsType == fatType32 {
+		if fs.ebpb32, err = parseEBPB32(probe); err != nil {
+			// The primary's EBPB failed to parse outright - possibly corrupted well beyond its
+			// trailing 0xAA55 signature. This first pass exists only to learn where the backup
+			// boot sector lives, so fall back to peeking that one field directly rather than
+			// failing the mount here, before readBootSector below ever gets a chance to try an
+			// otherwise-intact backup.
+			backup := peekBackupBootSector(probe)
+			if backup == 0 {
+				return nil, nil, err
+			}
+			fs.ebpb32 = &dos71EBPB{dos331BPB: bpb, backupBootSector: backup}
+		}
+	}
+
+	sector, err := fs.readBootSector(true)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid boot sector: %v", err)
+	}
+	if bpb, fsType, err = parseBPBAndType(sector); err != nil {
+		return nil, nil, err
+	}
+	fs.bpb, fs.fsType = bpb, fsType
+
+	var jmpBoot [3]byte
+	copy(jmpBoot[:], sector[0:3])
+
+	switch fs.fsType {
+	case fatType32:
+		if fs.ebpb32, err = parseEBPB32(sector); err != nil {
+			return nil, nil, err
+		}
+		if err := fs.loadFSInfo(); err != nil {
+			return nil, nil, fmt.Errorf("could not load FSInfo sector: %v", err)
+		}
+
+	case fatType12, fatType16:
+		ebpbLen, err := dos40EBPBLength(sector[bpbOffset+27])
+		if err != nil {
+			return nil, nil, err
+		}
+		ebpb, _, err := dos40EBPBFromBytes(sector[bpbOffset : bpbOffset+ebpbLen])
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not parse EBPB: %v", err)
+		}
+		fs.ebpb = ebpb
+
+		firstRootSector := uint32(bpb.reservedSectorCount) + uint32(bpb.numFATs)*uint32(bpb.sectorsPerFat16)
+		fs.rootDir = newFixedRootDirectory(bpb, firstRootSector)
+
+	default:
+		return nil, nil, fmt.Errorf("could not determine FAT type from boot sector geometry")
+	}
+
+	var validator bpbValidator = fs.bpb
+	if fs.fsType == fatType32 {
+		validator = fs.ebpb32
+	}
+	warnings, err = validateForMount(validator, jmpBoot, validateOptions{Tolerant: opts.Tolerant})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return fs, warnings, nil
+}
+
+// parseBPBAndType reads the common DOS 3.31 BPB out of a 512-byte boot sector and determines
+// which FAT type it describes, without committing to either EBPB form yet.
+func parseBPBAndType(sector []byte) (*dos331BPB, fatType, error) {
+	bpb, err := dos331BPBFromBytes(sector[bpbOffset : bpbOffset+25])
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not parse BPB: %v", err)
+	}
+	fatSz32 := binary.LittleEndian.Uint32(sector[bpbOffset+25 : bpbOffset+29])
+	return bpb, determineFatType(bpb, fatSz32), nil
+}
+
+// parseEBPB32 reads the FAT32 EBPB out of a 512-byte boot sector, looking up its length from the
+// extended boot signature byte first since dos71EBPBFromBytes needs an exact-length slice.
+func parseEBPB32(sector []byte) (*dos71EBPB, error) {
+	ebpbLen, err := dos71EBPBLength(sector[bpbOffset+55])
+	if err != nil {
+		return nil, err
+	}
+	ebpb32, _, err := dos71EBPBFromBytes(sector[bpbOffset : bpbOffset+ebpbLen])
+	if err != nil {
+		return nil, fmt.Errorf("could not parse FAT32 EBPB: %v", err)
+	}
+	return ebpb32, nil
+}
+
+// dos71EBPBLength maps a DOS 7.1 EBPB's extended boot signature byte to the byte length
+// dos71EBPBFromBytes expects, since that length can't be known before the signature is read.
+func dos71EBPBLength(extendedBootSignature byte) (int, error) {
+	switch extendedBootSignature {
+	case shortDos71EBPB:
+		return 60, nil
+	case longDos71EBPB:
+		return 79, nil
+	default:
+		return 0, fmt.Errorf("unrecognized FAT32 EBPB signature: %#02x", extendedBootSignature)
+	}
+}
+
+// dos40EBPBLength maps a DOS 4.0 EBPB's extended boot signature byte to the byte length
+// dos40EBPBFromBytes expects, since that length can't be known before the signature is read.
+func dos40EBPBLength(extendedBootSignature byte) (int, error) {
+	switch extendedBootSignature {
+	case shortDos40EBPB:
+		return 32, nil
+	case longDos40EBPB:
+		return 51, nil
+	default:
+		return 0, fmt.Errorf("unrecognized FAT12/16 EBPB signature: %#02x", extendedBootSignature)
+	}
+}
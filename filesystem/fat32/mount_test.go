@@ -0,0 +1,278 @@
+package fat32
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// buildBootSector serializes an already-encoded EBPB (which embeds its own DOS 3.31 BPB) into a
+// full 512-byte boot sector with a valid jmpBoot and signature, the way a real formatter would
+// lay one out.
+func buildBootSector(t *testing.T, ebpbBytes []byte) []byte {
+	t.Helper()
+	sector := make([]byte, 512)
+	copy(sector[0:3], []byte{0xEB, 0x58, 0x90})
+	copy(sector[3:11], []byte("MOUNTEST"))
+	copy(sector[bpbOffset:], ebpbBytes) // ebpbBytes embeds the DOS 3.31 BPB at its own offset 0
+	binary.LittleEndian.PutUint16(sector[bootSectorSignatureOffset:], bootSectorSignature)
+	return sector
+}
+
+func TestReadFAT16(t *testing.T) {
+	const sectorSize = 512
+	bpb := &dos331BPB{
+		bytesPerSector:      sectorSize,
+		sectorsPerCluster:   1,
+		reservedSectorCount: 1,
+		numFATs:             2,
+		rootEntryCount:      16,
+		sectorsPerFat16:     1,
+		media:               0xF8,
+		totalSectors16:      5000,
+	}
+	ebpb := &dos40EBPB{
+		dos331BPB:             bpb,
+		extendedBootSignature: shortDos40EBPB,
+	}
+	ebpbBytes, err := ebpb.toBytes()
+	if err != nil {
+		t.Fatalf("ebpb.toBytes: %v", err)
+	}
+	sector := buildBootSector(t, ebpbBytes)
+
+	data := make([]byte, int(bpb.totalSectors16)*sectorSize)
+	copy(data, sector)
+	dev := &fakeBlockDevice{data: data}
+
+	fs, err := Read(dev, int64(len(data)))
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if fs.FSType() != "FAT16" {
+		t.Errorf("FSType() = %q, want FAT16", fs.FSType())
+	}
+	if fs.ebpb == nil {
+		t.Fatal("ebpb not set")
+	}
+	wantFirstRootSector := uint32(bpb.reservedSectorCount) + uint32(bpb.numFATs)*uint32(bpb.sectorsPerFat16)
+	if fs.rootDir == nil || fs.rootDir.startSector != wantFirstRootSector {
+		t.Errorf("rootDir.startSector = %v, want %d", fs.rootDir, wantFirstRootSector)
+	}
+	if fs.rootDir.maxEntries != bpb.rootEntryCount {
+		t.Errorf("rootDir.maxEntries = %d, want %d", fs.rootDir.maxEntries, bpb.rootEntryCount)
+	}
+}
+
+func TestReadFAT32(t *testing.T) {
+	const sectorSize = 512
+	const reservedSectorCount = 32
+	const sectorsPerFAT = 1
+	const numFATs = 2
+	// countOfClusters must reach 65525 to classify as FAT32 under determineFatType's rule.
+	const dataSectors = 65525
+	totalSectors32 := uint32(reservedSectorCount + numFATs*sectorsPerFAT + dataSectors)
+
+	bpb := &dos331BPB{
+		bytesPerSector:      sectorSize,
+		sectorsPerCluster:   1,
+		reservedSectorCount: reservedSectorCount,
+		numFATs:             numFATs,
+		media:               0xF8,
+		totalSectors32:      totalSectors32,
+	}
+	ebpb32 := &dos71EBPB{
+		dos331BPB:             bpb,
+		sectorsPerFat:         sectorsPerFAT,
+		rootDirectoryCluster:  2,
+		fsInformationSector:   1,
+		extendedBootSignature: shortDos71EBPB,
+	}
+	ebpbBytes, err := ebpb32.toBytes()
+	if err != nil {
+		t.Fatalf("ebpb32.toBytes: %v", err)
+	}
+	sector := buildBootSector(t, ebpbBytes)
+
+	data := make([]byte, int64(totalSectors32)*sectorSize)
+	copy(data, sector)
+	fsis := &fsInfoSector{freeCount: 12345, nextFree: 2}
+	copy(data[int64(ebpb32.fsInformationSector)*sectorSize:], fsis.toBytes())
+
+	dev := &fakeBlockDevice{data: data}
+
+	fs, err := Read(dev, int64(len(data)))
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if fs.FSType() != "FAT32" {
+		t.Errorf("FSType() = %q, want FAT32", fs.FSType())
+	}
+	if fs.ebpb32 == nil || fs.ebpb32.rootDirectoryCluster != 2 {
+		t.Fatalf("ebpb32 = %+v, want rootDirectoryCluster 2", fs.ebpb32)
+	}
+	if free, ok := fs.FreeClusters(); !ok || free != 12345 {
+		t.Errorf("FreeClusters() = (%d, %v), want (12345, true)", free, ok)
+	}
+}
+
+func TestReadFAT32FallsBackToBackupBootSector(t *testing.T) {
+	const sectorSize = 512
+	const reservedSectorCount = 32
+	const sectorsPerFAT = 1
+	const numFATs = 2
+	const dataSectors = 65525
+	const backupBootSector = 6
+	totalSectors32 := uint32(reservedSectorCount + numFATs*sectorsPerFAT + dataSectors)
+
+	bpb := &dos331BPB{
+		bytesPerSector:      sectorSize,
+		sectorsPerCluster:   1,
+		reservedSectorCount: reservedSectorCount,
+		numFATs:             numFATs,
+		media:               0xF8,
+		totalSectors32:      totalSectors32,
+	}
+	ebpb32 := &dos71EBPB{
+		dos331BPB:             bpb,
+		sectorsPerFat:         sectorsPerFAT,
+		rootDirectoryCluster:  2,
+		backupBootSector:      backupBootSector,
+		extendedBootSignature: shortDos71EBPB,
+	}
+	ebpbBytes, err := ebpb32.toBytes()
+	if err != nil {
+		t.Fatalf("ebpb32.toBytes: %v", err)
+	}
+	sector := buildBootSector(t, ebpbBytes)
+
+	data := make([]byte, int64(totalSectors32)*sectorSize)
+	copy(data, sector)
+	copy(data[backupBootSector*sectorSize:], sector) // valid backup copy
+	// corrupt only the primary's signature, simulating a damaged primary boot sector.
+	data[bootSectorSignatureOffset] = 0x00
+	data[bootSectorSignatureOffset+1] = 0x00
+
+	dev := &fakeBlockDevice{data: data}
+
+	fs, err := Read(dev, int64(len(data)))
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if fs.FSType() != "FAT32" {
+		t.Errorf("FSType() = %q, want FAT32", fs.FSType())
+	}
+	if fs.ebpb32 == nil || fs.ebpb32.rootDirectoryCluster != 2 {
+		t.Fatalf("ebpb32 = %+v, want rootDirectoryCluster 2", fs.ebpb32)
+	}
+}
+
+// TestReadFAT32FallsBackWhenPrimaryEBPBCorrupted exercises a primary boot sector whose
+// corruption reaches past the trailing 0xAA55 signature into the EBPB itself (here, an invalid
+// version field), with an otherwise byte-for-byte intact backup at sector 6. Read must still
+// succeed by falling back to the backup: the preliminary pass over the primary only needs to
+// learn where that backup lives, not parse the primary's EBPB cleanly.
+func TestReadFAT32FallsBackWhenPrimaryEBPBCorrupted(t *testing.T) {
+	const sectorSize = 512
+	const reservedSectorCount = 32
+	const sectorsPerFAT = 1
+	const numFATs = 2
+	const dataSectors = 65525
+	const backupBootSector = 6
+	totalSectors32 := uint32(reservedSectorCount + numFATs*sectorsPerFAT + dataSectors)
+
+	bpb := &dos331BPB{
+		bytesPerSector:      sectorSize,
+		sectorsPerCluster:   1,
+		reservedSectorCount: reservedSectorCount,
+		numFATs:             numFATs,
+		media:               0xF8,
+		totalSectors32:      totalSectors32,
+	}
+	ebpb32 := &dos71EBPB{
+		dos331BPB:             bpb,
+		sectorsPerFat:         sectorsPerFAT,
+		rootDirectoryCluster:  2,
+		backupBootSector:      backupBootSector,
+		extendedBootSignature: shortDos71EBPB,
+	}
+	ebpbBytes, err := ebpb32.toBytes()
+	if err != nil {
+		t.Fatalf("ebpb32.toBytes: %v", err)
+	}
+	sector := buildBootSector(t, ebpbBytes)
+
+	data := make([]byte, int64(totalSectors32)*sectorSize)
+	copy(data, sector)
+	copy(data[backupBootSector*sectorSize:], sector) // valid backup copy
+
+	// corrupt the primary's EBPB version field (bytes 31:33 of the EBPB) in addition to zeroing
+	// its signature, so only a genuine fallback to the backup - not just a retry of the primary -
+	// can make this mount succeed.
+	data[bpbOffset+31] = 0xFF
+	data[bpbOffset+32] = 0xFF
+	data[bootSectorSignatureOffset] = 0x00
+	data[bootSectorSignatureOffset+1] = 0x00
+
+	dev := &fakeBlockDevice{data: data}
+
+	fs, err := Read(dev, int64(len(data)))
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if fs.FSType() != "FAT32" {
+		t.Errorf("FSType() = %q, want FAT32", fs.FSType())
+	}
+	if fs.ebpb32 == nil || fs.ebpb32.rootDirectoryCluster != 2 {
+		t.Fatalf("ebpb32 = %+v, want rootDirectoryCluster 2", fs.ebpb32)
+	}
+}
+
+func TestReadRejectsInvalidBootSector(t *testing.T) {
+	const sectorSize = 512
+	bpb := &dos331BPB{
+		bytesPerSector:      sectorSize,
+		sectorsPerCluster:   1,
+		reservedSectorCount: 1,
+		numFATs:             2,
+		rootEntryCount:      16,
+		sectorsPerFat16:     1,
+		media:               0xF8,
+		totalSectors16:      5000,
+	}
+	ebpb := &dos40EBPB{
+		dos331BPB:             bpb,
+		extendedBootSignature: shortDos40EBPB,
+	}
+	ebpbBytes, err := ebpb.toBytes()
+	if err != nil {
+		t.Fatalf("ebpb.toBytes: %v", err)
+	}
+	sector := buildBootSector(t, ebpbBytes)
+	sector[0] = 0x00 // corrupt jmpBoot so it fails Validate
+
+	data := make([]byte, int(bpb.totalSectors16)*sectorSize)
+	copy(data, sector)
+	dev := &fakeBlockDevice{data: data}
+
+	if _, err := Read(dev, int64(len(data))); err == nil {
+		t.Error("Read() error = nil, want a validation error for a corrupt jmpBoot")
+	}
+
+	fs, warnings, err := ReadWithOptions(dev, int64(len(data)), ReadOptions{Tolerant: true})
+	if err != nil {
+		t.Fatalf("ReadWithOptions(Tolerant: true): unexpected error: %v", err)
+	}
+	if fs == nil {
+		t.Fatal("ReadWithOptions(Tolerant: true) returned a nil FileSystem")
+	}
+	if len(warnings) == 0 {
+		t.Error("ReadWithOptions(Tolerant: true) returned no warnings for a corrupt jmpBoot")
+	}
+}
+
+func TestReadTooSmall(t *testing.T) {
+	dev := &fakeBlockDevice{data: make([]byte, 10)}
+	if _, err := Read(dev, 10); err == nil {
+		t.Error("Read() error = nil, want an error for a too-small volume")
+	}
+}
@@ -0,0 +1,188 @@
+package fat32
+
+import (
+	"fmt"
+	"strings"
+)
+
+// createRecoveryEntry writes a directory entry claiming startCluster/size under path (e.g.
+// "FOUND.000/FILE0001.CHK"), creating the FOUND.000 directory in the root if it does not
+// already exist. Both path components must already fit the 8.3 form fsck_msdosfs uses for its
+// own recovery names, so no long-filename entries are needed.
+//
+// fat is mutated in place for any cluster this allocates, rather than read fresh from disk and
+// written back here: it is Repair's single in-progress FAT snapshot, and a second independent
+// read-modify-write round trip here would clobber whatever Repair has already changed in it.
+func (fs *FileSystem) createRecoveryEntry(fat []byte, path string, startCluster, size uint32) error {
+	parts := strings.SplitN(path, "/", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("recovery path %q must be of the form DIR/NAME", path)
+	}
+	dirName, fileName := parts[0], parts[1]
+
+	dirCluster, err := fs.ensureRootSubdirectory(fat, dirName)
+	if err != nil {
+		return fmt.Errorf("could not prepare %s: %v", dirName, err)
+	}
+
+	entry := make([]byte, 32)
+	name, err := to83Name(fileName)
+	if err != nil {
+		return err
+	}
+	copy(entry[0:11], name[:])
+	entry[11] = 0x20 // archive bit; this is a plain recovered file, not a directory
+	entry[20] = uint8(startCluster >> 16)
+	entry[21] = uint8(startCluster >> 24)
+	entry[26] = uint8(startCluster)
+	entry[27] = uint8(startCluster >> 8)
+	entry[28] = uint8(size)
+	entry[29] = uint8(size >> 8)
+	entry[30] = uint8(size >> 16)
+	entry[31] = uint8(size >> 24)
+
+	return fs.appendDirectoryEntry(fat, []uint32{dirCluster}, entry)
+}
+
+// to83Name converts a plain filename already in 8.3 form into the padded 11-byte directory
+// entry representation.
+func to83Name(name string) ([11]byte, error) {
+	var out [11]byte
+	for i := range out {
+		out[i] = ' '
+	}
+	base := name
+	ext := ""
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		base, ext = name[:idx], name[idx+1:]
+	}
+	if len(base) > 8 || len(ext) > 3 {
+		return out, fmt.Errorf("name %q does not fit the 8.3 short name format", name)
+	}
+	copy(out[0:8], strings.ToUpper(base))
+	copy(out[8:11], strings.ToUpper(ext))
+	return out, nil
+}
+
+// ensureRootSubdirectory returns the starting cluster of a single-level directory directly
+// under the root, allocating one cluster and a new root entry for it if it does not already
+// exist. Like createRecoveryEntry, it allocates against and mutates the caller's fat slice
+// rather than its own fresh read of the on-disk FAT.
+func (fs *FileSystem) ensureRootSubdirectory(fat []byte, name string) (uint32, error) {
+	root, err := fs.rootClusters()
+	if err != nil {
+		return 0, err
+	}
+	entries, err := fs.readRootEntries(root)
+	if err != nil {
+		return 0, err
+	}
+	for _, e := range entries {
+		if e.isEnd() {
+			break
+		}
+		if !e.isDeleted() && !e.isLongName() && e.isDirectory() && e.shortName() == name {
+			return e.firstCluster(), nil
+		}
+	}
+
+	cluster, err := fs.allocateCluster(fat, fs.countOfClusters())
+	if err != nil {
+		return 0, fmt.Errorf("could not allocate cluster for %s: %v", name, err)
+	}
+
+	dot := make([]byte, fs.bytesPerCluster())
+	dotName, _ := to83Name(".")
+	copy(dot[0:11], dotName[:])
+	dot[11] = attrDirectory
+	dot[26] = uint8(cluster)
+	dot[27] = uint8(cluster >> 8)
+	dotdotName, _ := to83Name("..")
+	copy(dot[32:43], dotdotName[:])
+	dot[32+11] = attrDirectory
+
+	if _, err := fs.file.WriteAt(dot, fs.clusterOffset(cluster)); err != nil {
+		return 0, fmt.Errorf("could not initialize %s: %v", name, err)
+	}
+
+	dirEntry := make([]byte, 32)
+	entryName, err := to83Name(name)
+	if err != nil {
+		return 0, err
+	}
+	copy(dirEntry[0:11], entryName[:])
+	dirEntry[11] = attrDirectory
+	dirEntry[20] = uint8(cluster >> 16)
+	dirEntry[21] = uint8(cluster >> 24)
+	dirEntry[26] = uint8(cluster)
+	dirEntry[27] = uint8(cluster >> 8)
+	if err := fs.appendDirectoryEntry(fat, root, dirEntry); err != nil {
+		return 0, fmt.Errorf("could not link %s into root: %v", name, err)
+	}
+
+	return cluster, nil
+}
+
+// readRootEntries reads the entries of the root directory, whether it is cluster-chained
+// (FAT32) or a fixed-size region (FAT12/16).
+func (fs *FileSystem) readRootEntries(root []uint32) ([]dirEntry, error) {
+	fat, err := fs.readFAT()
+	if err != nil {
+		return nil, err
+	}
+	w := &fsckWalker{fs: fs, fat: fat}
+	return w.readDirectoryEntries(root)
+}
+
+// appendDirectoryEntry writes entry into the first free or deleted slot in the directory
+// rooted at clusters, extending the chain by one cluster if every existing slot is in use. If it
+// needs to allocate that extra cluster, it does so against and mutates the caller's fat slice
+// rather than its own fresh read of the on-disk FAT (fat is unused, and may be nil, for the
+// fixed-size FAT12/16 root case, which never allocates).
+func (fs *FileSystem) appendDirectoryEntry(fat []byte, clusters []uint32, entry []byte) error {
+	bpc := fs.bytesPerCluster()
+
+	if len(clusters) == 0 {
+		// fixed-size FAT12/16 root: scan directly for a free slot, no allocation possible.
+		size := int64(fs.rootDir.sectorCount) * int64(fs.bpb.bytesPerSector)
+		base := int64(fs.rootDir.startSector) * int64(fs.bpb.bytesPerSector)
+		buf := make([]byte, size)
+		if _, err := fs.file.ReadAt(buf, base); err != nil {
+			return err
+		}
+		for off := int64(0); off+32 <= size; off += 32 {
+			if buf[off] == entryFreeMarker || buf[off] == entryDeletedMarker {
+				_, err := fs.file.WriteAt(entry, base+off)
+				return err
+			}
+		}
+		return fmt.Errorf("root directory is full")
+	}
+
+	cluster := clusters[0]
+	var lastCluster uint32
+	for cluster >= 2 && !isEndOfChain(fs.fsType, cluster) {
+		data, err := fs.readCluster(cluster)
+		if err != nil {
+			return err
+		}
+		for off := 0; off+32 <= len(data); off += 32 {
+			if data[off] == entryFreeMarker || data[off] == entryDeletedMarker {
+				_, err := fs.file.WriteAt(entry, fs.clusterOffset(cluster)+int64(off))
+				return err
+			}
+		}
+		lastCluster = cluster
+		cluster = fatEntryGet(fs.fsType, fat, cluster)
+	}
+
+	next, err := fs.allocateCluster(fat, fs.countOfClusters())
+	if err != nil {
+		return fmt.Errorf("could not extend directory: %v", err)
+	}
+	fatEntrySet(fs.fsType, fat, lastCluster, next)
+	empty := make([]byte, bpc)
+	copy(empty, entry)
+	_, err = fs.file.WriteAt(empty, fs.clusterOffset(next))
+	return err
+}
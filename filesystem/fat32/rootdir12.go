@@ -0,0 +1,27 @@
+package fat32
+
+// fixedRootDirectory describes the fixed-size root directory region used by FAT12 and FAT16
+// volumes. Unlike FAT32, where the root directory is just another cluster chain starting at
+// rootDirectoryCluster, FAT12/16 reserve a fixed run of sectors for it immediately following
+// the FAT copies, sized to hold exactly rootEntryCount 32-byte directory entries.
+type fixedRootDirectory struct {
+	startSector uint32 // StartSector is the first sector of the root directory region, relative to the start of the volume
+	sectorCount uint16 // SectorCount is the number of sectors reserved for the root directory
+	maxEntries  uint16 // MaxEntries is the maximum number of 32-byte directory entries the region can hold
+}
+
+// newFixedRootDirectory computes the fixed root directory region for a FAT12/16 volume, given
+// the sector immediately following the last FAT copy.
+func newFixedRootDirectory(bpb *dos331BPB, firstSector uint32) *fixedRootDirectory {
+	return &fixedRootDirectory{
+		startSector: firstSector,
+		sectorCount: rootDirectorySectors(bpb),
+		maxEntries:  bpb.rootEntryCount,
+	}
+}
+
+// dataRegionStart returns the first sector of the cluster data region, immediately following
+// this root directory region.
+func (r *fixedRootDirectory) dataRegionStart() uint32 {
+	return r.startSector + uint32(r.sectorCount)
+}
@@ -0,0 +1,156 @@
+package fat32
+
+import "fmt"
+
+// ValidationCode identifies which specific BPB/EBPB rule a ValidationError reports, so that
+// callers can filter or selectively downgrade individual checks (e.g. a tolerant reader that
+// accepts a non-standard OEM jump but still rejects a corrupt geometry).
+type ValidationCode string
+
+// The validation codes below mirror the checks performed by fsck_msdosfs and Fuchsia's thinfs
+// bootrecord package before trusting a FAT boot sector.
+const (
+	ErrJmpBoot           ValidationCode = "jmp-boot"
+	ErrBytesPerSector    ValidationCode = "bytes-per-sector"
+	ErrSectorsPerCluster ValidationCode = "sectors-per-cluster"
+	ErrClusterSize       ValidationCode = "cluster-size"
+	ErrNumFATs           ValidationCode = "num-fats"
+	ErrReservedSectors   ValidationCode = "reserved-sectors"
+	ErrFAT32Geometry     ValidationCode = "fat32-geometry"
+	ErrRootCluster       ValidationCode = "root-cluster"
+	ErrExtendedBootSig   ValidationCode = "extended-boot-signature"
+	ErrFileSystemType    ValidationCode = "file-system-type"
+)
+
+// ValidationError reports a single violation of the expected FAT BPB/EBPB layout.
+type ValidationError struct {
+	Code    ValidationCode
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+func newValidationError(code ValidationCode, format string, args ...interface{}) *ValidationError {
+	return &ValidationError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// validateJmpBoot checks the 3-byte x86 jump instruction that every FAT boot sector begins
+// with: either a short jump (0xEB, displacement, 0x90) or a near jump (0xE9, displacement lo,
+// displacement hi).
+func validateJmpBoot(jmpBoot [3]byte) []error {
+	var errs []error
+	switch jmpBoot[0] {
+	case 0xEB:
+		if jmpBoot[2] != 0x90 {
+			errs = append(errs, newValidationError(ErrJmpBoot, "short jump at jmpBoot[0]=0xEB must have NOP at jmpBoot[2], got %#02x", jmpBoot[2]))
+		}
+	case 0xE9:
+		// near jump, displacement bytes are unconstrained
+	default:
+		errs = append(errs, newValidationError(ErrJmpBoot, "jmpBoot[0] must be 0xEB or 0xE9, got %#02x", jmpBoot[0]))
+	}
+	return errs
+}
+
+// Validate checks a DOS 3.31 BPB against the geometry constraints common to every FAT type:
+// a valid jmpBoot opcode, valid sector size, a power-of-two cluster size that keeps the
+// cluster itself within 32KiB, at least one FAT, and a sane reserved sector count. jmpBoot is
+// passed in rather than read from bpb itself, since it lives in the full boot sector, ahead of
+// where the BPB proper begins.
+func (bpb *dos331BPB) Validate(jmpBoot [3]byte) []error {
+	var errs []error
+	if bpb == nil {
+		return []error{newValidationError(ErrBytesPerSector, "BPB is nil")}
+	}
+
+	errs = append(errs, validateJmpBoot(jmpBoot)...)
+
+	switch bpb.bytesPerSector {
+	case 512, 1024, 2048, 4096:
+	default:
+		errs = append(errs, newValidationError(ErrBytesPerSector, "BytesPerSec must be one of 512, 1024, 2048, 4096, got %d", bpb.bytesPerSector))
+	}
+
+	spc := bpb.sectorsPerCluster
+	if spc == 0 || spc > 128 || (spc&(spc-1)) != 0 {
+		errs = append(errs, newValidationError(ErrSectorsPerCluster, "SecPerClus must be a power of two in [1, 128], got %d", spc))
+	} else if uint32(bpb.bytesPerSector)*uint32(spc) > 32768 {
+		errs = append(errs, newValidationError(ErrClusterSize, "BytesPerSec * SecPerClus must not exceed 32768, got %d", uint32(bpb.bytesPerSector)*uint32(spc)))
+	}
+
+	if bpb.numFATs < 1 {
+		errs = append(errs, newValidationError(ErrNumFATs, "NumFATs must be at least 1, got %d", bpb.numFATs))
+	}
+
+	isFAT32 := bpb.rootEntryCount == 0 && bpb.totalSectors16 == 0 && bpb.sectorsPerFat16 == 0
+	switch {
+	case isFAT32 && bpb.reservedSectorCount != 32:
+		errs = append(errs, newValidationError(ErrReservedSectors, "RsvdSecCnt is typically 32 for FAT32, got %d", bpb.reservedSectorCount))
+	case !isFAT32 && bpb.reservedSectorCount < 1:
+		errs = append(errs, newValidationError(ErrReservedSectors, "RsvdSecCnt must be at least 1, got %d", bpb.reservedSectorCount))
+	}
+
+	return errs
+}
+
+// Validate checks a DOS 7.1 EBPB (FAT32) against the constraints fsck_msdosfs applies beyond
+// the embedded BPB: the BPB must itself describe a FAT32 geometry, the root directory cluster
+// must be a valid allocatable cluster, the extended boot signature must be recognized, and - for
+// the long form - the filesystem type string must read "FAT32   ".
+func (bpb *dos71EBPB) Validate(jmpBoot [3]byte) []error {
+	var errs []error
+	if bpb == nil {
+		return []error{newValidationError(ErrRootCluster, "EBPB is nil")}
+	}
+	errs = append(errs, bpb.dos331BPB.Validate(jmpBoot)...)
+
+	if bpb.dos331BPB != nil && !(bpb.dos331BPB.rootEntryCount == 0 && bpb.dos331BPB.totalSectors16 == 0 && bpb.dos331BPB.sectorsPerFat16 == 0) {
+		errs = append(errs, newValidationError(ErrFAT32Geometry, "RootEntCnt, TotSec16 and FATSz16 must all be zero for FAT32"))
+	}
+
+	if bpb.rootDirectoryCluster < 2 {
+		errs = append(errs, newValidationError(ErrRootCluster, "rootDirectoryCluster must be at least 2, got %d", bpb.rootDirectoryCluster))
+	}
+
+	switch bpb.extendedBootSignature {
+	case shortDos71EBPB, longDos71EBPB:
+	default:
+		errs = append(errs, newValidationError(ErrExtendedBootSig, "extendedBootSignature must be 0x28 or 0x29, got %#02x", bpb.extendedBootSignature))
+	}
+
+	if bpb.extendedBootSignature == longDos71EBPB && bpb.fileSystemType != fileSystemTypeFAT32 {
+		errs = append(errs, newValidationError(ErrFileSystemType, "fileSystemType must be %q for a long-form FAT32 EBPB, got %q", fileSystemTypeFAT32, bpb.fileSystemType))
+	}
+
+	return errs
+}
+
+// validateOptions controls how mount-time validation failures are handled.
+type validateOptions struct {
+	// Tolerant downgrades validation errors to warnings: mount proceeds, but the warnings are
+	// still returned for the caller to inspect or log.
+	Tolerant bool
+}
+
+// bpbValidator is implemented by both dos331BPB (FAT12/16) and dos71EBPB (FAT32), letting
+// validateForMount apply the same tolerant/strict handling to either BPB form a mounted volume
+// turns out to use.
+type bpbValidator interface {
+	Validate(jmpBoot [3]byte) []error
+}
+
+// validateForMount runs bpb.Validate and, depending on opts.Tolerant, either fails mount on any
+// error or returns them as non-fatal warnings. jmpBoot is the 3-byte jump opcode read from the
+// start of the boot sector, ahead of the BPB/EBPB this function otherwise validates.
+func validateForMount(bpb bpbValidator, jmpBoot [3]byte, opts validateOptions) (warnings []error, err error) {
+	errs := bpb.Validate(jmpBoot)
+	if len(errs) == 0 {
+		return nil, nil
+	}
+	if opts.Tolerant {
+		return errs, nil
+	}
+	return nil, fmt.Errorf("boot sector failed validation: %v", errs[0])
+}